@@ -0,0 +1,50 @@
+// Command zombebattle starts zombebattle server described by JSON
+// configuration file. See engine/config package for configuration format.
+//
+//	zombebattle -config server.json
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sheirys/zombebattle/engine/config"
+)
+
+func main() {
+	path := flag.String("config", "zombebattle.json", "path to server configuration file")
+	check := flag.Bool("check", false, "validate configuration file and exit")
+	flag.Parse()
+
+	cfg, err := config.LoadFile(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server, err := cfg.NewServer()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *check {
+		log.Printf("configuration %s is valid", *path)
+		return
+	}
+
+	// stop the server gracefully when process is interrupted.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+		<-stop
+		cancel()
+	}()
+
+	if err := server.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+const (
+	// DefaultChatLimit and DefaultChatInterval are used when server chat
+	// limits are not set. By default player can send 5 messages in 10
+	// seconds.
+	DefaultChatLimit    = 5
+	DefaultChatInterval = 10 * time.Second
+)
+
+// ChatFilter can change or reject chat message before it is delivered, e.g.
+// mask profanity. Text is returned as it should be delivered. If error is
+// returned, message is not delivered and sender is notified about error.
+type ChatFilter func(from types.Player, text string) (string, error)
+
+// WordFilter will create ChatFilter that masks given words with `*`. Words
+// are matched case-insensitively.
+func WordFilter(words ...string) ChatFilter {
+	banned := map[string]bool{}
+	for _, w := range words {
+		banned[strings.ToLower(w)] = true
+	}
+	return func(from types.Player, text string) (string, error) {
+		fields := strings.Fields(text)
+		for i, f := range fields {
+			if banned[strings.ToLower(f)] {
+				fields[i] = strings.Repeat("*", len(f))
+			}
+		}
+		return strings.Join(fields, " "), nil
+	}
+}
+
+// chatCommands are chat commands handled by server.
+var chatCommands = []types.Command{
+	{
+		Name:  types.EventSay,
+		Args:  []types.Arg{{Name: "text", Variadic: true}},
+		Help:  "say something to everyone in the room",
+		Scope: types.ScopeRoom,
+	},
+	{
+		Name: types.EventTell,
+		Args: []types.Arg{
+			{Name: "name"},
+			{Name: "text", Variadic: true},
+		},
+		Help:  "send private message to player",
+		Scope: types.ScopeLobby | types.ScopeRoom,
+	},
+	{
+		Name:  types.EventShout,
+		Args:  []types.Arg{{Name: "text", Variadic: true}},
+		Help:  "say something to everyone in the lobby",
+		Scope: types.ScopeLobby,
+	},
+}
+
+// registerChat will register chat commands and their handlers.
+func (s *Server) registerChat() {
+	handlers := map[string]CommandHandler{
+		types.EventSay:   s.say,
+		types.EventTell:  s.tell,
+		types.EventShout: s.shout,
+	}
+	for _, c := range chatCommands {
+		s.commands.Register(c)
+		s.handlers[c.Name] = handlers[c.Name]
+	}
+}
+
+// say will send message to everyone in the room of the player, spectators
+// included.
+func (s *Server) say(player types.Player, e types.Event) error {
+	client := player.(*Client)
+	room := s.roomOf(client)
+	if room == nil {
+		return errors.New("you are not in the room, use SHOUT to talk in the lobby")
+	}
+	chat, err := s.chatEvent(client, types.ChatRoom, e.Args)
+	if err != nil {
+		return err
+	}
+	recipients := room.Players()
+	if w, ok := room.(types.Watchable); ok {
+		recipients = append(recipients, w.Spectators()...)
+	}
+	for _, p := range recipients {
+		p.ProcessEvent(chat)
+	}
+	return nil
+}
+
+// tell will send private message to player with given name.
+func (s *Server) tell(player types.Player, e types.Event) error {
+	client := player.(*Client)
+	s.clientsMtx.Lock()
+	to, ok := s.names[strings.ToLower(e.Actor)]
+	s.clientsMtx.Unlock()
+	if !ok {
+		return errNoSuchPlayer(e.Actor)
+	}
+	chat, err := s.chatEvent(client, types.ChatTell, e.Args)
+	if err != nil {
+		return err
+	}
+	to.ProcessEvent(chat)
+	return nil
+}
+
+// shout will send message to everyone in the lobby.
+func (s *Server) shout(player types.Player, e types.Event) error {
+	client := player.(*Client)
+	chat, err := s.chatEvent(client, types.ChatLobby, e.Args)
+	if err != nil {
+		return err
+	}
+	for _, c := range s.lobbyClients() {
+		c.ProcessEvent(chat)
+	}
+	return nil
+}
+
+// chatEvent will produce CHAT event from message words. Message is checked
+// against rate limit and ChatFilter.
+func (s *Server) chatEvent(client *Client, channel string, words []string) (types.Event, error) {
+	if len(words) == 0 {
+		return types.Event{}, types.ErrWrongArity
+	}
+	if !client.allowChat(s.chatLimit(), s.chatInterval(), time.Now()) {
+		return types.Event{}, types.ErrRateLimited
+	}
+
+	text := strings.Join(words, " ")
+	if s.ChatFilter != nil {
+		var err error
+		if text, err = s.ChatFilter(client, text); err != nil {
+			return types.Event{}, err
+		}
+	}
+
+	return types.Event{
+		Type:  types.EventChat,
+		Actor: client.Name(),
+		Args:  []string{channel, text},
+	}, nil
+}
+
+// allowChat will return true if client sent less than limit messages during
+// interval. Message is counted only if it is allowed.
+func (c *Client) allowChat(limit int, interval time.Duration, now time.Time) bool {
+	recent := c.chat[:0]
+	for _, t := range c.chat {
+		if now.Sub(t) < interval {
+			recent = append(recent, t)
+		}
+	}
+	c.chat = recent
+	if len(c.chat) >= limit {
+		return false
+	}
+	c.chat = append(c.chat, now)
+	return true
+}
+
+func (s *Server) chatLimit() int {
+	if s.ChatLimit <= 0 {
+		return DefaultChatLimit
+	}
+	return s.ChatLimit
+}
+
+func (s *Server) chatInterval() time.Duration {
+	if s.ChatInterval <= 0 {
+		return DefaultChatInterval
+	}
+	return s.ChatInterval
+}
+
+// errNoSuchPlayer will return protocol error for player that is not online.
+func errNoSuchPlayer(name string) error {
+	return &types.ProtocolError{
+		Code: types.ErrCodeNoSuchPlayer,
+		Msg:  fmt.Sprintf("player '%s' is not online", name),
+	}
+}
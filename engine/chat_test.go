@@ -0,0 +1,100 @@
+package engine_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine"
+	"github.com/sheirys/zombebattle/engine/rooms"
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+func TestWordFilter(t *testing.T) {
+	filter := engine.WordFilter("brains", "Darn")
+	text, err := filter(nil, "darn  zombies want BRAINS")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if text != "**** zombies want ******" {
+		t.Errorf("wrong filtered text: '%s'", text)
+	}
+}
+
+func TestServerChat(t *testing.T) {
+	server := &engine.Server{
+		Addr: "127.0.0.1:0",
+		DefaultRoom: &rooms.TrainingGrounds{
+			Zombies: []types.Zombie{
+				&zombies.Dummy{},
+			},
+		},
+		ChatLimit:    3,
+		ChatInterval: time.Hour,
+		ChatFilter: func(from types.Player, text string) (string, error) {
+			if strings.Contains(text, "spam") {
+				return "", errors.New("no spam please")
+			}
+			return engine.WordFilter("brains")(from, text)
+		},
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	dial := func(start string) (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", server.ListenAddr().String())
+		if err != nil {
+			t.Fatalf("cannot connect to server: %s", err)
+		}
+		reader := bufio.NewReader(conn)
+		waitForLine(t, reader, "# new world.")
+		if start != "" {
+			conn.Write([]byte("start " + start + "\n"))
+			waitForLine(t, reader, "JOINED "+start)
+		}
+		return conn, reader
+	}
+
+	alice, aliceReader := dial("alice")
+	defer alice.Close()
+	bob, bobReader := dial("bob")
+	defer bob.Close()
+	lobby, lobbyReader := dial("")
+	defer lobby.Close()
+
+	alice.Write([]byte("say need more brains\n"))
+	waitForLine(t, bobReader, "CHAT alice room need more ******")
+	waitForLine(t, aliceReader, "CHAT alice room need more ******")
+
+	bob.Write([]byte("tell ALICE behind you\n"))
+	waitForLine(t, aliceReader, "CHAT bob tell behind you")
+
+	bob.Write([]byte("tell carol hi\n"))
+	waitForLine(t, bobReader, "ERR NO_SUCH_PLAYER player 'carol' is not online")
+
+	lobby.Write([]byte("shout anyone here?\n"))
+	waitForLine(t, lobbyReader, "CHAT guest#3 lobby anyone here?")
+
+	lobby.Write([]byte("say hello\n"))
+	waitForLine(t, lobbyReader, "ERR WRONG_CONTEXT")
+	alice.Write([]byte("shout hello\n"))
+	waitForLine(t, aliceReader, "ERR WRONG_CONTEXT")
+
+	alice.Write([]byte("say spam\n"))
+	waitForLine(t, aliceReader, "# no spam please")
+	alice.Write([]byte("say one\n"))
+	waitForLine(t, aliceReader, "CHAT alice room one")
+	alice.Write([]byte("say two\n"))
+	waitForLine(t, aliceReader, "ERR RATE_LIMITED")
+}
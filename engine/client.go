@@ -1,108 +1,361 @@
 package engine
 
 import (
-	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"log"
 	"net"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/sheirys/zombebattle/engine/types"
 )
 
-// Client holds telnet connection for player.
+// Client holds telnet connection for player. Messages to client are queued
+// and written by single writer goroutine, so they arrive in the same order as
+// they were sent and slow client does not block rooms. QueueSize limits how
+// many messages can wait in the queue and WriteTimeout limits how long one
+// write can take. SlowClientPolicy decides what happens when queue is full.
 type Client struct {
-	Name         string
+	id           string
+	name         string
+	nameMtx      sync.Mutex
 	Conn         net.Conn
 	eventStream  chan types.Event
+	streamMtx    sync.Mutex
 	selectedRoom string
+
+	// Commands are commands that client can use in the lobby. If
+	// Commands is nil, only built-in commands are known.
+	Commands     *CommandSet
+	roomCommands *CommandSet
+
+	QueueSize        int
+	WriteTimeout     time.Duration
+	SlowClientPolicy SlowClientPolicy
+	out              *outbox
+	dropped          int // messages dropped on previous connections.
+	outMtx           sync.Mutex
+
+	// MaxLineLength limits how long line client can send. Zero means
+	// DefaultMaxLineLength.
+	MaxLineLength int
+	reader        *LineReader
+
+	// LobbyIdleTimeout and GameIdleTimeout define how long client can
+	// stay silent in the lobby or in the room before it is disconnected.
+	// Any line, e.g. `PING`, keeps client alive. Zero means no timeout.
+	LobbyIdleTimeout time.Duration
+	GameIdleTimeout  time.Duration
+
+	// token is used to resume session from new connection. resumed is set
+	// when this connection was handed over to resumed session.
+	token   string
+	resumed bool
+
+	// watching is set when client selected room with `WATCH` command.
+	// zombie is set when client selected room with
+	// `JOIN <room> AS ZOMBIE` command.
+	watching bool
+	zombie   bool
+
+	// chat holds times of recent chat messages for rate limiting.
+	chat []time.Time
+
+	// quit is closed when client is dropped, so client does not wait for
+	// room that is not reading events anymore.
+	quit     chan struct{}
+	quitOnce sync.Once
+	dropOnce sync.Once
 }
 
-// Run starts to handle connection messages.
-func (c *Client) Run() {
+// errDropped will be returned by Run when client is dropped while room is
+// not reading his events.
+var errDropped = errors.New("client is dropped")
+
+// Attach will prepare new event stream for the room client is joining.
+// Commands are commands that client can use in this room. Attach should be
+// called before client is added into room.
+func (c *Client) Attach(commands *CommandSet) {
+	c.streamMtx.Lock()
 	c.eventStream = make(chan types.Event)
+	c.roomCommands = commands
+	c.streamMtx.Unlock()
+}
+
+// Detach will close event stream, so room knows that this client is gone.
+// Detach should be called when client leaves the room.
+func (c *Client) Detach() {
+	c.streamMtx.Lock()
+	close(c.eventStream)
+	c.streamMtx.Unlock()
+}
+
+// Run starts to handle connection messages. Run will block until client
+// leaves the room with `LEAVE` command or disconnects. Nil is returned when
+// client left the room. Event stream is not closed, so Run can be called
+// again when player resumes session from new connection. Each event is
+// offered to server func first, and if server does not handle it, event is
+// passed to the room. If server returns error, client will be notified about
+// it.
+func (c *Client) Run(server func(types.Event) (bool, error)) error {
+	stream := c.stream()
+	commands := c.commandSet(c.roomCommands)
 	for {
-		input, err := bufio.NewReader(c.Conn).ReadBytes('\n')
+		input, err := c.readLine(c.GameIdleTimeout)
+		if err == types.ErrLineTooLong {
+			c.reportError(err)
+			continue
+		}
 		if err != nil {
 			log.Printf("client disconnected")
-			return
+			return err
 		}
-		event, err := Parse(input)
+		if len(bytes.TrimSpace(input)) == 0 {
+			continue
+		}
+		event, err := commands.Parse(input, types.ScopeRoom)
 		if err != nil {
 			log.Printf("parse error command: %s", err)
+			c.reportError(err)
 			continue
 		}
 
 		switch event.Type {
-		case types.EventStart:
-			c.Name = event.Actor
+		case types.EventHelp:
+			c.Notify(commands.Help(types.ScopeRoom))
+			continue
+		case types.EventLeave:
+			return nil
+		case types.EventPing:
+			c.pong(event)
+			continue
 		case types.EventShoot:
-			event.Actor = c.Name
+			event.Actor = c.Name()
+		}
+
+		if server != nil {
+			handled, err := server(event)
+			if err != nil {
+				c.reportError(err)
+			}
+			if handled {
+				continue
+			}
+		}
+		select {
+		case stream <- event:
+		case <-c.quitChan():
+			return errDropped
 		}
-		c.eventStream <- event
 	}
 }
 
 // WaitForStart will block until client produces START event. Before that
 // client can select room where he wants to join with `JOIN` command or
-// create new world with `NEW` command.
-func (c *Client) WaitForStart(server chan types.Event) error {
+// create new world with `NEW` command. Lobby commands `LIST`, `WHO` and
+// `INFO` can be used to look around. Commands that should be handled by
+// server will be passed to server func. If server returns error, client will
+// be notified about it. WaitForStart also returns when server accepts
+// `WATCH <room>` or `RESUME <token>` command.
+func (c *Client) WaitForStart(server func(types.Event) error) error {
+	commands := c.commandSet(c.Commands)
 	for {
-		input, err := bufio.NewReader(c.Conn).ReadBytes('\n')
+		input, err := c.readLine(c.LobbyIdleTimeout)
+		if err == types.ErrLineTooLong {
+			c.reportError(err)
+			continue
+		}
 		if err != nil {
 			log.Printf("client disconnected")
 			return err
 		}
-		event, err := Parse(input)
+		if len(bytes.TrimSpace(input)) == 0 {
+			continue
+		}
+		event, err := commands.Parse(input, types.ScopeLobby)
 		if err != nil {
 			log.Printf("parse error command: %s", err)
+			c.reportError(err)
 			continue
 		}
-		if event.Type == types.EventJoin {
+
+		switch event.Type {
+		case types.EventJoin:
+			// let server check if client can join this room.
+			if err := server(event); err != nil {
+				c.reportError(err)
+				continue
+			}
 			c.selectedRoom = event.Actor
-		}
-		if event.Type == types.EventNew {
+			c.zombie = len(event.Args) > 0
+			if c.zombie {
+				c.Notify("# selected room " + event.Actor + ", you will play as zombie\n")
+				continue
+			}
+			c.Notify("# selected room " + event.Actor + "\n")
+		case types.EventNew:
 			// if client wants to create a new room send this
 			// command to server, so server creates new room.
-			server <- event
-		}
-		if event.Type == types.EventStart {
-			c.Name = event.Actor
+			if err := server(event); err != nil {
+				c.reportError(err)
+				continue
+			}
+			c.Notify("# created room " + event.Actor + "\n")
+		case types.EventHelp:
+			c.Notify(commands.Help(types.ScopeLobby))
+		case types.EventPing:
+			c.pong(event)
+		case types.EventStart:
+			// let server check if this name can be used.
+			if err := server(event); err != nil {
+				c.reportError(err)
+				continue
+			}
+			c.setName(event.Actor)
+			return nil
+		case types.EventWatch:
+			// let server check if this room can be watched.
+			if err := server(event); err != nil {
+				c.reportError(err)
+				continue
+			}
+			c.selectedRoom = event.Actor
+			c.watching = true
+			c.zombie = false
 			return nil
+		case types.EventResume:
+			// server will hand this connection over to the
+			// resumed session.
+			if err := server(event); err != nil {
+				c.reportError(err)
+				continue
+			}
+			return nil
+		default:
+			// lobby commands and commands registered in server
+			// will be handled by server.
+			if err := server(event); err != nil {
+				c.reportError(err)
+			}
 		}
 	}
 }
 
+// ResetRoom will forget room selected by client.
+func (c *Client) ResetRoom() {
+	c.selectedRoom = ""
+	c.watching = false
+	c.zombie = false
+}
+
+// Watching will return true if client wants to watch selected room as
+// spectator.
+func (c *Client) Watching() bool {
+	return c.watching
+}
+
+// AsZombie will return true if client wants to play selected room as zombie.
+func (c *Client) AsZombie() bool {
+	return c.zombie
+}
+
 // ShowLobby will show possible rooms to client. Client should select room
 // with `JOIN <room>` before starting game. If client does not select room
-// then player will be forced to join to default room.
-func (c *Client) ShowLobby(lobby []types.Lobby) {
+// then player will be forced to join to default room. Room types are shown,
+// so client knows what rooms can be created with `NEW` command.
+func (c *Client) ShowLobby(lobby []types.Lobby, roomTypes []string) {
 	msg := "# Please select room from list above. If you do not\n"
 	msg += "# select the room, after `START <name>` you will be\n"
 	msg += "# forced into default room. Please select room with\n"
 	msg += "# `JOIN <room>` command.\n"
 	msg += "# \n"
+	msg += roomList(lobby)
+	msg += "# \n"
+	msg += "# you can use `NEW <name> [type] [key=value...]` to create a\n"
+	msg += "# new world. Available types: " + strings.Join(roomTypes, ", ") + "\n"
+	msg += "# use `LIST` to refresh this list, `WHO <room>` to see who is\n"
+	msg += "# playing and `INFO <room>` to read room rules. Use\n"
+	msg += "# `WATCH <room>` to watch the game without playing. Type\n"
+	msg += "# `HELP` to see all commands.\n"
+	c.send([]byte(msg), false)
+}
 
-	for _, room := range lobby {
-		msg += "#    " + room.Name
-		if room.Default {
-			msg += " (default)"
-		}
-		msg += "\n"
+// ShowRooms will show rooms with their state, players, zombies and scores.
+// This is response to `LIST` command.
+func (c *Client) ShowRooms(lobby []types.Lobby) {
+	c.send([]byte(roomList(lobby)), false)
+}
+
+// ShowPlayers will show names of players, players that play as zombies and
+// spectators that are in the room. This is response to `WHO <room>` command.
+func (c *Client) ShowPlayers(room string, names, zombies, spectators []string) {
+	msg := "# there are no players in " + room + "\n"
+	if len(names) > 0 {
+		msg = "# players in " + room + ": " + strings.Join(names, ", ") + "\n"
+	}
+	if len(zombies) > 0 {
+		msg += "# zombies in " + room + ": " + strings.Join(zombies, ", ") + "\n"
+	}
+	if len(spectators) > 0 {
+		msg += "# spectators in " + room + ": " + strings.Join(spectators, ", ") + "\n"
 	}
+	c.Notify(msg)
+}
 
-	msg += "# \n"
-	msg += "# you can use `NEW <name>` to create a new world.\n"
-	c.Conn.Write([]byte(msg))
+// ShowInfo will show room details and rules. This is response to
+// `INFO <room>` command.
+func (c *Client) ShowInfo(room types.Lobby) {
+	msg := roomList([]types.Lobby{room})
+	if room.Rules != "" {
+		msg += "# Rules: " + room.Rules + "\n"
+	}
+	c.send([]byte(msg), false)
+}
+
+// ID will return session ID given by server for this connection.
+func (c *Client) ID() string {
+	return c.id
+}
+
+// Name will return name client has chosen with `START` command. Before
+// that client is named as guest by server.
+func (c *Client) Name() string {
+	c.nameMtx.Lock()
+	defer c.nameMtx.Unlock()
+	return c.name
+}
+
+// setName will change name of the client.
+func (c *Client) setName(name string) {
+	c.nameMtx.Lock()
+	c.name = name
+	c.nameMtx.Unlock()
 }
 
 // Notify will send cotification to client. This is used by room to print
 // various information to client.
 func (c *Client) Notify(msg string) {
-	c.Conn.Write([]byte(msg))
+	c.send([]byte(msg), false)
 }
 
-// Drop will disconnect client
+// Drop will disconnect client. Messages that are already queued will be
+// written before connection is closed.
 func (c *Client) Drop() {
-	c.Conn.Close()
+	c.dropOnce.Do(func() {
+		close(c.quitChan())
+	})
+	c.flushAndClose()
+}
+
+// quitChan will return channel that is closed when client is dropped.
+func (c *Client) quitChan() chan struct{} {
+	c.quitOnce.Do(func() {
+		c.quit = make(chan struct{})
+	})
+	return c.quit
 }
 
 // SelectedRoom will return room name that client wants to join.
@@ -110,21 +363,129 @@ func (c *Client) SelectedRoom() string {
 	return c.selectedRoom
 }
 
-// GetEvent will pass action what client is trying to do. When we parse input
-// from client, we will transform input to event, and all events will be queued
-// into this channel.
-func (c *Client) GetEvent() (types.Event, bool) {
-	event, ok := <-c.eventStream
-	return event, ok
+// EventStream will pass actions what client is trying to do. When we parse
+// input from client, we will transform input to event, and all events will be
+// queued into this channel. Each Attach creates new stream, so room that
+// got stream when client was added never reads events meant for other room.
+func (c *Client) EventStream() <-chan types.Event {
+	return c.stream()
 }
 
 // ProcessEvent will handle event passed by room. For example if zombie dies
-// or other player is shooting or someone wins the room.
+// or other player is shooting or someone wins the room. Event is queued, so
+// ProcessEvent does not block. WALK events can be dropped if client is too
+// slow, see SlowClientPolicy.
 func (c *Client) ProcessEvent(e types.Event) {
-	c.Conn.Write([]byte(e.String() + "\n"))
+	c.send([]byte(e.String()+"\n"), e.Type == types.EventWalk)
 }
 
 // ProduceEvent will add event into clients event stream.
 func (c *Client) ProduceEvent(e types.Event) {
-	c.eventStream <- e
+	c.stream() <- e
+}
+
+// readLine will read next line from connection. The same reader is used in
+// the lobby and in the room, so commands sent in one write are not lost. If
+// timeout is set and client does not send anything in this period, client is
+// notified and error is returned.
+func (c *Client) readLine(timeout time.Duration) ([]byte, error) {
+	if c.reader == nil {
+		c.reader = NewLineReader(c.Conn, c.MaxLineLength)
+	}
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	c.Conn.SetReadDeadline(deadline)
+
+	line, err := c.reader.ReadLine()
+	if isTimeout(err) {
+		log.Printf("client %s was idle for %s", c.id, timeout)
+		c.Notify("# you were idle for too long, disconnecting\n")
+	}
+	return line, err
+}
+
+// takeOver will move connection of other client to this client, so player can
+// continue the game from new connection. Previous connection of this client is
+// closed. Other client must not be used after this.
+func (c *Client) takeOver(other *Client) {
+	out := other.outbound()
+
+	c.outMtx.Lock()
+	old := c.out
+	c.out = out
+	c.Conn = other.Conn
+	c.reader = other.reader
+	c.outMtx.Unlock()
+
+	if old == nil {
+		return
+	}
+	old.mtx.Lock()
+	dropped := old.dropped
+	old.close()
+	old.mtx.Unlock()
+
+	c.outMtx.Lock()
+	c.dropped += dropped
+	c.outMtx.Unlock()
+}
+
+// pong will respond to `PING` command with the same token.
+func (c *Client) pong(ping types.Event) {
+	c.ProcessEvent(types.Event{Type: types.EventPong, Args: ping.Args})
+}
+
+// commandSet will return given command set or built-in commands if set is
+// nil.
+func (c *Client) commandSet(set *CommandSet) *CommandSet {
+	if set == nil {
+		return defaultCommands
+	}
+	return set
+}
+
+// reportError will send error to client. Protocol errors are sent as `ERR`
+// event and other errors as notification.
+func (c *Client) reportError(err error) {
+	if e, ok := err.(*types.ProtocolError); ok {
+		c.ProcessEvent(e.Event())
+		return
+	}
+	c.Notify("# " + err.Error() + "\n")
+}
+
+// stream will return event stream of the room client is attached to.
+func (c *Client) stream() chan types.Event {
+	c.streamMtx.Lock()
+	defer c.streamMtx.Unlock()
+	return c.eventStream
+}
+
+// isTimeout will return true if err is network timeout.
+func isTimeout(err error) bool {
+	e, ok := err.(net.Error)
+	return ok && e.Timeout()
+}
+
+// roomList will format lobby rooms, one room per line e.g.:
+//
+//	#    THE-WALL [wall] running, 2 players, 3 zombies, players 1/5, zombies 0/5 (default)
+func roomList(lobby []types.Lobby) (msg string) {
+	for _, room := range lobby {
+		msg += "#    " + room.Name
+		if room.Type != "" {
+			msg += " [" + room.Type + "]"
+		}
+		msg += fmt.Sprintf(" %s, %d players, %d zombies", room.State, room.Players, room.Zombies)
+		for _, score := range room.Scores {
+			msg += ", " + score.String()
+		}
+		if room.Default {
+			msg += " (default)"
+		}
+		msg += "\n"
+	}
+	return
 }
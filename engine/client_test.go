@@ -0,0 +1,66 @@
+package engine_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine"
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+func TestClientEventStream(t *testing.T) {
+	client := &engine.Client{}
+
+	client.Attach(nil)
+	first := client.EventStream()
+	client.Detach()
+
+	client.Attach(nil)
+	second := client.EventStream()
+	defer client.Detach()
+
+	// room that got stream before client left should see it closed and
+	// should not get events meant for the new room.
+	if _, open := <-first; open {
+		t.Fatalf("stream of previous room should be closed")
+	}
+	go client.ProduceEvent(types.Event{Type: types.EventShoot})
+	select {
+	case e := <-second:
+		if e.Type != types.EventShoot {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("event was not passed to current stream")
+	}
+}
+
+func TestClientDropUnblocksRun(t *testing.T) {
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	client := &engine.Client{Conn: conn}
+	client.Attach(nil)
+	defer client.Detach()
+
+	result := make(chan error)
+	go func() {
+		result <- client.Run(nil)
+	}()
+
+	// nobody reads the stream, so client waits until it is dropped.
+	peer.Write([]byte("shoot 1 1\n"))
+	go io.Copy(ioutil.Discard, peer)
+	client.Drop()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Errorf("expected error when client is dropped")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Run is blocked after client was dropped")
+	}
+}
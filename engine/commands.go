@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+// builtinCommands holds commands known by server. Rooms can add their own
+// commands by implementing types.CommandProvider.
+var builtinCommands = []types.Command{
+	{
+		Name:  types.EventStart,
+		Args:  []types.Arg{{Name: "name"}},
+		Help:  "start the game with given name",
+		Scope: types.ScopeLobby,
+	},
+	{
+		Name: types.EventJoin,
+		Args: []types.Arg{
+			{Name: "room"},
+			{Name: "AS", Optional: true},
+			{Name: "ZOMBIE", Optional: true},
+		},
+		Help:  "select room to join after START, AS ZOMBIE to play zombie",
+		Scope: types.ScopeLobby,
+	},
+	{
+		Name: types.EventNew,
+		Args: []types.Arg{
+			{Name: "room"},
+			{Name: "type", Optional: true},
+			{Name: "key=value", Variadic: true},
+		},
+		Help:  "create new room",
+		Scope: types.ScopeLobby,
+	},
+	{
+		Name:  types.EventList,
+		Help:  "list rooms",
+		Scope: types.ScopeLobby,
+	},
+	{
+		Name:  types.EventWho,
+		Args:  []types.Arg{{Name: "room"}},
+		Help:  "list players in the room",
+		Scope: types.ScopeLobby,
+	},
+	{
+		Name:  types.EventInfo,
+		Args:  []types.Arg{{Name: "room"}},
+		Help:  "show rules of the room",
+		Scope: types.ScopeLobby,
+	},
+	{
+		Name:  types.EventWatch,
+		Args:  []types.Arg{{Name: "room"}},
+		Help:  "watch the room without playing",
+		Scope: types.ScopeLobby,
+	},
+	{
+		Name:  types.EventResume,
+		Args:  []types.Arg{{Name: "token"}},
+		Help:  "return to the game after disconnect",
+		Scope: types.ScopeLobby,
+	},
+	{
+		Name: types.EventShoot,
+		Args: []types.Arg{
+			{Name: "x", Kind: types.ArgCoord},
+			{Name: "y", Kind: types.ArgCoord},
+		},
+		Help:  "shoot at given coordinates",
+		Scope: types.ScopeRoom,
+	},
+	{
+		Name:    types.EventRematch,
+		Aliases: []string{"RESTART"},
+		Help:    "vote for another round when game is over",
+		Scope:   types.ScopeRoom,
+	},
+	{
+		Name:  types.EventLeave,
+		Help:  "leave the room and return to the lobby",
+		Scope: types.ScopeRoom,
+	},
+	{
+		Name:  types.EventPing,
+		Args:  []types.Arg{{Name: "token", Optional: true}},
+		Help:  "server responds with PONG [token]",
+		Scope: types.ScopeLobby | types.ScopeRoom,
+	},
+	{
+		Name:  types.EventHelp,
+		Help:  "show this help",
+		Scope: types.ScopeLobby | types.ScopeRoom,
+	},
+}
+
+// defaultCommands is used by Parse and Help.
+var defaultCommands = NewCommandSet()
+
+// CommandSet is registry of commands that client can type. It is used to
+// parse client input and to describe what client can type, so help never
+// drifts from what is actually accepted. CommandSet is safe for concurrent
+// use.
+type CommandSet struct {
+	commands []types.Command
+	mtx      sync.RWMutex
+}
+
+// NewCommandSet will create command registry with built-in server commands.
+func NewCommandSet() *CommandSet {
+	set := &CommandSet{}
+	for _, c := range builtinCommands {
+		set.Register(c)
+	}
+	return set
+}
+
+// Register will add command into registry. Commands without scope can be used
+// both in the lobby and in the room. Error is returned if command name or any
+// alias is already taken.
+func (s *CommandSet) Register(c types.Command) error {
+	if c.Name == "" {
+		return fmt.Errorf("command name is required")
+	}
+	c.Name = strings.ToUpper(c.Name)
+	if c.Scope == 0 {
+		c.Scope = types.ScopeLobby | types.ScopeRoom
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, name := range append([]string{c.Name}, c.Aliases...) {
+		if _, ok := s.find(name); ok {
+			return fmt.Errorf("command '%s' is already registered", strings.ToUpper(name))
+		}
+	}
+	s.commands = append(s.commands, c)
+	return nil
+}
+
+// With will return copy of this registry extended with given commands.
+// Commands without scope will be available in the room. Commands that
+// conflict with already registered ones are skipped.
+func (s *CommandSet) With(commands ...types.Command) *CommandSet {
+	s.mtx.RLock()
+	set := &CommandSet{
+		commands: append([]types.Command(nil), s.commands...),
+	}
+	s.mtx.RUnlock()
+
+	for _, c := range commands {
+		if c.Scope == 0 {
+			c.Scope = types.ScopeRoom
+		}
+		set.Register(c)
+	}
+	return set
+}
+
+// Parse will parse client input and produce event from it. Only commands of
+// given scope are accepted, types.ErrWrongContext is returned for commands
+// that cannot be used there.
+func (s *CommandSet) Parse(b []byte, scope types.Scope) (types.Event, error) {
+	args := strings.Fields(string(b))
+	if len(args) == 0 {
+		return types.Event{}, types.ErrUnknownCommand
+	}
+
+	// commands should be case-insensitive, but arguments keep their
+	// original case, so player and room names are displayed as typed.
+	s.mtx.RLock()
+	c, ok := s.find(args[0])
+	s.mtx.RUnlock()
+	if !ok {
+		return types.Event{}, types.ErrUnknownCommand
+	}
+	if c.Scope&scope == 0 {
+		return types.Event{}, types.ErrWrongContext
+	}
+	return c.Parse(args[1:])
+}
+
+// Help will describe commands that can be used in given scope e.g.:
+//
+//	# available commands:
+//	#    SHOOT <x> <y>                      shoot at given coordinates
+func (s *CommandSet) Help(scope types.Scope) string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	msg := "# available commands:\n"
+	for _, c := range s.commands {
+		if c.Scope&scope == 0 {
+			continue
+		}
+		msg += fmt.Sprintf("#    %-34s %s\n", c.Usage(), c.Help)
+	}
+	return msg
+}
+
+// find will return command by its name or alias.
+func (s *CommandSet) find(name string) (types.Command, bool) {
+	for _, c := range s.commands {
+		if c.Is(name) {
+			return c, true
+		}
+	}
+	return types.Command{}, false
+}
+
+// Help will describe built-in commands that can be used in given scope.
+func Help(scope types.Scope) string {
+	return defaultCommands.Help(scope)
+}
@@ -0,0 +1,127 @@
+package engine_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sheirys/zombebattle/engine"
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+func TestHelp(t *testing.T) {
+	testTable := []struct {
+		Scope     types.Scope
+		Listed    []string
+		NotListed []string
+	}{
+		{
+			Scope:     types.ScopeLobby,
+			Listed:    []string{"START <name>", "JOIN <room>", "NEW <room>", "LIST", "WHO <room>", "INFO <room>", "HELP"},
+			NotListed: []string{"SHOOT", "REMATCH", "LEAVE"},
+		},
+		{
+			Scope:     types.ScopeRoom,
+			Listed:    []string{"SHOOT <x> <y>", "REMATCH", "LEAVE", "HELP"},
+			NotListed: []string{"START", "JOIN", "NEW", "LIST"},
+		},
+	}
+
+	for idx, c := range testTable {
+		help := engine.Help(c.Scope)
+		for _, usage := range c.Listed {
+			if !strings.Contains(help, "#    "+usage) {
+				t.Errorf("command not listed: case %d, want: '%s', got:\n%s", idx, usage, help)
+			}
+		}
+		for _, usage := range c.NotListed {
+			if strings.Contains(help, "#    "+usage) {
+				t.Errorf("command should not be listed: case %d, command: '%s', got:\n%s", idx, usage, help)
+			}
+		}
+	}
+}
+
+func TestCommandSet(t *testing.T) {
+	set := engine.NewCommandSet()
+
+	dance := types.Command{
+		Name:    "dance",
+		Aliases: []string{"BOOGIE"},
+		Args:    []types.Arg{{Name: "style"}},
+		Help:    "dance in the lobby",
+		Scope:   types.ScopeLobby,
+	}
+	if err := set.Register(dance); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := set.Register(types.Command{Name: "SHOOT"}); err == nil {
+		t.Errorf("expected error when registering built-in command twice")
+	}
+	if err := set.Register(types.Command{Name: "JIVE", Aliases: []string{"boogie"}}); err == nil {
+		t.Errorf("expected error when registering taken alias")
+	}
+
+	event, err := set.Parse([]byte("boogie disco\n"), types.ScopeLobby)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if event.Type != "DANCE" || event.Actor != "disco" {
+		t.Errorf("incorrect event: %+v", event)
+	}
+	if !strings.Contains(set.Help(types.ScopeLobby), "#    DANCE <style>") {
+		t.Errorf("registered command is not listed in help")
+	}
+
+	// commands are accepted only in their scope.
+	if _, err := set.Parse([]byte("dance disco"), types.ScopeRoom); err != types.ErrWrongContext {
+		t.Errorf("lobby command should not be accepted in the room, got: %v", err)
+	}
+	if _, err := set.Parse([]byte("shoot 1 1"), types.ScopeLobby); err != types.ErrWrongContext {
+		t.Errorf("room command should not be accepted in the lobby, got: %v", err)
+	}
+	if err := set.Register(types.Command{Name: "WAVE"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, scope := range []types.Scope{types.ScopeLobby, types.ScopeRoom} {
+		if _, err := set.Parse([]byte("wave"), scope); err != nil {
+			t.Errorf("command without scope should be accepted in scope %d, got: %v", scope, err)
+		}
+	}
+
+	// room commands are available only in extended set.
+	room := set.With(types.Command{Name: "HIDE", Help: "hide from zombies"})
+	if _, err := set.Parse([]byte("hide"), types.ScopeRoom); err != types.ErrUnknownCommand {
+		t.Errorf("room command should not be known outside room, got: %v", err)
+	}
+	if event, err := room.Parse([]byte("hide"), types.ScopeRoom); err != nil || event.Type != "HIDE" {
+		t.Errorf("incorrect room command: event: %+v, err: %v", event, err)
+	}
+	if !strings.Contains(room.Help(types.ScopeRoom), "#    HIDE") {
+		t.Errorf("room command is not listed in room help")
+	}
+}
+
+func TestCommandSetErrors(t *testing.T) {
+	set := engine.NewCommandSet()
+	testTable := []struct {
+		Input       string
+		ExpectedErr error
+	}{
+		{"shoot a 2b", types.ErrNotANumber},
+		{"shoot 1 2b", types.ErrNotANumber},
+		{"shoot -1 2", types.ErrOutOfRange},
+		{"shoot 1", types.ErrWrongArity},
+		{"who", types.ErrWrongArity},
+		{"start", types.ErrWrongArity},
+		{"fat mama", types.ErrUnknownCommand},
+		{"jashgkjhdlkfjhaluefshalusf", types.ErrUnknownCommand},
+		{"", types.ErrUnknownCommand},
+	}
+
+	for i, v := range testTable {
+		_, err := set.Parse([]byte(v.Input), types.ScopeLobby|types.ScopeRoom)
+		if err != v.ExpectedErr {
+			t.Errorf("incorrect error. case: %d got: %v want: %v", i, err, v.ExpectedErr)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+// Package config describes zombebattle server with JSON configuration file,
+// so server setup can be changed without recompiling. Only JSON format is
+// supported. Example:
+//
+//	{
+//		"addr": ":3333",
+//		"shutdown_timeout": "5s",
+//		"default_room": {
+//			"type": "training",
+//			"zombies": [{"type": "dummy"}]
+//		},
+//		"rooms": [
+//			{
+//				"name": "THE-WALL",
+//				"type": "wall",
+//				"options": {"kills": 10, "tick": "1s"},
+//				"zombies": [{"type": "crawler", "count": 2}, {"type": "armored", "hp": 5}]
+//			}
+//		],
+//		"limits": {"max_clients": 100, "max_rooms": 20, "room_idle_timeout": "10m", "game_idle_timeout": "5m", "zombie_cooldown": "1s"},
+//		"chat": {"limit": 5, "interval": "10s", "banned_words": ["brains"]}
+//	}
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine"
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+// DefaultAddr is used when configuration does not define listen address.
+const DefaultAddr = ":3333"
+
+// Config describes whole server.
+type Config struct {
+	Addr            string `json:"addr"`
+	ShutdownTimeout string `json:"shutdown_timeout"`
+	DefaultRoom     *Room  `json:"default_room"`
+	Rooms           []Room `json:"rooms"`
+	Limits          Limits `json:"limits"`
+	Chat            Chat   `json:"chat"`
+}
+
+// Chat describes chat settings. Limit is how many messages player can send
+// during Interval. BannedWords are masked in chat messages.
+type Chat struct {
+	Limit       int      `json:"limit"`
+	Interval    string   `json:"interval"`
+	BannedWords []string `json:"banned_words"`
+}
+
+// Room describes room that will be created when server starts. Options are
+// the same `key=value` options that can be passed with `NEW` command.
+type Room struct {
+	Name    string                 `json:"name"`
+	Type    string                 `json:"type"`
+	Options map[string]interface{} `json:"options"`
+	Zombies []Zombie               `json:"zombies"`
+}
+
+// Zombie describes zombies that will be summoned in room when server starts.
+// If count is not set, one zombie will be summoned. HP sets health of armored
+// zombies and bosses.
+type Zombie struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+	HP    int    `json:"hp"`
+}
+
+// Limits describes server limits. Zero means no limit. RoomIdleTimeout is a
+// duration e.g. "10m" after which empty rooms are removed. LobbyIdleTimeout
+// and GameIdleTimeout are durations after which silent clients are
+// disconnected. ResumeTimeout is a duration during which disconnected player
+// can resume session. ZombieCooldown is a duration between moves of players
+// that play as zombies.
+type Limits struct {
+	MaxClients       int    `json:"max_clients"`
+	MaxRooms         int    `json:"max_rooms"`
+	RoomIdleTimeout  string `json:"room_idle_timeout"`
+	LobbyIdleTimeout string `json:"lobby_idle_timeout"`
+	GameIdleTimeout  string `json:"game_idle_timeout"`
+	ResumeTimeout    string `json:"resume_timeout"`
+	ZombieCooldown   string `json:"zombie_cooldown"`
+}
+
+// Error is returned when configuration is not valid. Key points to the
+// offending configuration key e.g. `rooms[1].type`.
+type Error struct {
+	Key string
+	Msg string
+}
+
+func (e *Error) Error() string {
+	if e.Key == "" {
+		return "config: " + e.Msg
+	}
+	return "config: " + e.Key + ": " + e.Msg
+}
+
+// LoadFile will load configuration from JSON file.
+func LoadFile(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Load(bytes.NewReader(b))
+}
+
+// Load will decode configuration from JSON. Unknown keys are not allowed.
+func Load(r io.Reader) (*Config, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, decodeError(b, err)
+	}
+	return cfg, nil
+}
+
+// NewServer will build new server from this configuration.
+func (c *Config) NewServer() (*engine.Server, error) {
+	server := &engine.Server{}
+	if err := c.Apply(server); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// Apply will configure given server. Rooms are created from room types
+// registered in server, so custom room types must be registered before Apply
+// is called.
+func (c *Config) Apply(s *engine.Server) error {
+	if c.Limits.MaxClients < 0 {
+		return &Error{Key: "limits.max_clients", Msg: "cannot be negative"}
+	}
+	if c.Limits.MaxRooms < 0 {
+		return &Error{Key: "limits.max_rooms", Msg: "cannot be negative"}
+	}
+	shutdownTimeout, err := parseDuration("shutdown_timeout", c.ShutdownTimeout)
+	if err != nil {
+		return err
+	}
+	roomIdleTimeout, err := parseDuration("limits.room_idle_timeout", c.Limits.RoomIdleTimeout)
+	if err != nil {
+		return err
+	}
+	lobbyIdleTimeout, err := parseDuration("limits.lobby_idle_timeout", c.Limits.LobbyIdleTimeout)
+	if err != nil {
+		return err
+	}
+	gameIdleTimeout, err := parseDuration("limits.game_idle_timeout", c.Limits.GameIdleTimeout)
+	if err != nil {
+		return err
+	}
+	resumeTimeout, err := parseDuration("limits.resume_timeout", c.Limits.ResumeTimeout)
+	if err != nil {
+		return err
+	}
+	zombieCooldown, err := parseDuration("limits.zombie_cooldown", c.Limits.ZombieCooldown)
+	if err != nil {
+		return err
+	}
+	if c.Chat.Limit < 0 {
+		return &Error{Key: "chat.limit", Msg: "cannot be negative"}
+	}
+	chatInterval, err := parseDuration("chat.interval", c.Chat.Interval)
+	if err != nil {
+		return err
+	}
+
+	var serverRooms []types.ServerRoom
+	names := map[string]string{}
+
+	if c.DefaultRoom != nil {
+		r, err := c.DefaultRoom.build(s, "default_room")
+		if err != nil {
+			return err
+		}
+		r.Default = true
+		names[strings.ToLower(r.Room.Name())] = "default_room"
+		serverRooms = append(serverRooms, r)
+	}
+
+	for i, room := range c.Rooms {
+		key := "rooms[" + strconv.Itoa(i) + "]"
+		if room.Name == "" {
+			return &Error{Key: key + ".name", Msg: "room name is required"}
+		}
+		r, err := room.build(s, key)
+		if err != nil {
+			return err
+		}
+		if other, ok := names[strings.ToLower(r.Room.Name())]; ok {
+			return &Error{Key: key + ".name", Msg: fmt.Sprintf("room '%s' is already defined in %s", r.Room.Name(), other)}
+		}
+		names[strings.ToLower(r.Room.Name())] = key
+		serverRooms = append(serverRooms, r)
+	}
+
+	if c.Limits.MaxRooms > 0 && len(serverRooms) > c.Limits.MaxRooms {
+		return &Error{Key: "limits.max_rooms", Msg: fmt.Sprintf("%d rooms are defined but only %d are allowed", len(serverRooms), c.Limits.MaxRooms)}
+	}
+
+	s.Addr = c.Addr
+	if s.Addr == "" {
+		s.Addr = DefaultAddr
+	}
+	s.ShutdownTimeout = shutdownTimeout
+	s.MaxClients = c.Limits.MaxClients
+	s.MaxRooms = c.Limits.MaxRooms
+	s.RoomIdleTimeout = roomIdleTimeout
+	s.LobbyIdleTimeout = lobbyIdleTimeout
+	s.GameIdleTimeout = gameIdleTimeout
+	s.ResumeTimeout = resumeTimeout
+	s.ZombieCooldown = zombieCooldown
+	s.ChatLimit = c.Chat.Limit
+	s.ChatInterval = chatInterval
+	if len(c.Chat.BannedWords) > 0 {
+		s.ChatFilter = engine.WordFilter(c.Chat.BannedWords...)
+	}
+	for _, r := range serverRooms {
+		if r.Default {
+			s.DefaultRoom = r.Room
+		}
+		s.AddRoom(r)
+	}
+	return nil
+}
+
+// build will create room described in configuration. Key is used to point to
+// this room in validation errors.
+func (r *Room) build(s *engine.Server, key string) (types.ServerRoom, error) {
+	if r.Type == "" {
+		return types.ServerRoom{}, &Error{Key: key + ".type", Msg: "room type is required"}
+	}
+	if !hasRoomType(s, r.Type) {
+		return types.ServerRoom{}, &Error{Key: key + ".type", Msg: fmt.Sprintf("unknown room type '%s', expected one of: %s", r.Type, strings.Join(s.RoomTypes(), ", "))}
+	}
+
+	var args []string
+	for _, name := range optionNames(r.Options) {
+		value, err := optionValue(r.Options[name])
+		if err != nil {
+			return types.ServerRoom{}, &Error{Key: key + ".options." + name, Msg: err.Error()}
+		}
+		args = append(args, name+"="+value)
+	}
+	opts, err := types.ParseRoomOptions(args)
+	if err != nil {
+		return types.ServerRoom{}, &Error{Key: key + ".options", Msg: err.Error()}
+	}
+
+	room, err := s.NewRoom(r.Type, opts)
+	if err != nil {
+		// point to the offending option if room tells which one it is.
+		okey := key + ".options"
+		if e, ok := err.(*types.OptionError); ok {
+			okey += "." + optionName(r.Options, e.Key)
+		}
+		return types.ServerRoom{}, &Error{Key: okey, Msg: err.Error()}
+	}
+	if r.Name != "" {
+		room.SetName(r.Name)
+	}
+
+	serverRoom := types.ServerRoom{
+		Room: room,
+		Type: strings.ToLower(r.Type),
+	}
+
+	for i, z := range r.Zombies {
+		zkey := key + ".zombies[" + strconv.Itoa(i) + "]"
+		if z.Count < 0 {
+			return types.ServerRoom{}, &Error{Key: zkey + ".count", Msg: "cannot be negative"}
+		}
+		if z.HP < 0 {
+			return types.ServerRoom{}, &Error{Key: zkey + ".hp", Msg: "cannot be negative"}
+		}
+		count := z.Count
+		if count == 0 {
+			count = 1
+		}
+		for n := 0; n < count; n++ {
+			zombie, err := zombies.New(z.Type)
+			if err != nil {
+				return types.ServerRoom{}, &Error{Key: zkey + ".type", Msg: fmt.Sprintf("%s, expected one of: %s", err, strings.Join(zombies.Kinds(), ", "))}
+			}
+			if z.HP > 0 {
+				switch zombie := zombie.(type) {
+				case *zombies.Armored:
+					zombie.MaxHP = z.HP
+				case *zombies.Boss:
+					zombie.MaxHP = z.HP
+				default:
+					return types.ServerRoom{}, &Error{Key: zkey + ".hp", Msg: fmt.Sprintf("zombie type '%s' does not have armor", z.Type)}
+				}
+			}
+			serverRoom.Zombies = append(serverRoom.Zombies, zombie)
+		}
+	}
+
+	return serverRoom, nil
+}
+
+// optionNames will return room option names in sorted order, so rooms always
+// get options in the same order.
+func optionNames(options map[string]interface{}) []string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// optionName will return option name as it is written in configuration.
+// Room option keys are lower-cased, so names are compared case-insensitively.
+func optionName(options map[string]interface{}, key string) string {
+	for name := range options {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+	}
+	return key
+}
+
+// optionValue will format room option value as it would be typed in `NEW`
+// command. Numbers are never formatted in exponent form.
+func optionValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("must be a string, number or bool")
+}
+
+// parseDuration will parse non-negative duration from configuration. Empty
+// value means zero duration.
+func parseDuration(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, &Error{Key: key, Msg: fmt.Sprintf("bad duration '%s', expected e.g. \"5s\"", value)}
+	}
+	return d, nil
+}
+
+// hasRoomType will return true if room type is registered in server.
+func hasRoomType(s *engine.Server, kind string) bool {
+	for _, name := range s.RoomTypes() {
+		if name == strings.ToLower(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeError will convert JSON decoding error into Error with line number
+// or offending key when possible.
+func decodeError(b []byte, err error) error {
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return &Error{Msg: fmt.Sprintf("line %d: %s", lineAt(b, e.Offset), e)}
+	case *json.UnmarshalTypeError:
+		return &Error{Key: fieldKey(e.Field), Msg: fmt.Sprintf("line %d: cannot use %s as %s", lineAt(b, e.Offset), e.Value, e.Type)}
+	}
+	return &Error{Msg: strings.TrimPrefix(err.Error(), "json: ")}
+}
+
+// fieldKey will convert field path reported by JSON decoder e.g.
+// `rooms.0.zombies.0.count` into configuration key `rooms[0].zombies[0].count`.
+func fieldKey(field string) string {
+	var key string
+	for _, part := range strings.Split(field, ".") {
+		switch _, err := strconv.Atoi(part); {
+		case err == nil:
+			key += "[" + part + "]"
+		case key == "":
+			key = part
+		default:
+			key += "." + part
+		}
+	}
+	return key
+}
+
+// lineAt will return line number of given offset in b.
+func lineAt(b []byte, offset int64) int {
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	return bytes.Count(b[:offset], []byte("\n")) + 1
+}
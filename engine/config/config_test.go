@@ -0,0 +1,186 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/config"
+	"github.com/sheirys/zombebattle/engine/rooms"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+func TestNewServer(t *testing.T) {
+	cfg, err := config.Load(strings.NewReader(`{
+		"addr": ":4444",
+		"shutdown_timeout": "2s",
+		"default_room": {
+			"type": "training",
+			"zombies": [{"type": "dummy"}]
+		},
+		"rooms": [
+			{
+				"name": "WALL1",
+				"type": "wall",
+				"options": {"kills": 1000000, "tick": "1s"},
+				"zombies": [{"type": "crawler", "count": 3}, {"type": "armored", "hp": 5}]
+			}
+		],
+		"limits": {"max_clients": 10, "max_rooms": 5, "room_idle_timeout": "1m", "lobby_idle_timeout": "2m", "game_idle_timeout": "30s", "resume_timeout": "1m", "zombie_cooldown": "2s"},
+		"chat": {"limit": 3, "interval": "5s", "banned_words": ["brains"]}
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected load error: %s", err)
+	}
+
+	server, err := cfg.NewServer()
+	if err != nil {
+		t.Fatalf("unexpected build error: %s", err)
+	}
+
+	if server.Addr != ":4444" {
+		t.Errorf("wrong addr: got: '%s', want: ':4444'", server.Addr)
+	}
+	if server.ShutdownTimeout != 2*time.Second {
+		t.Errorf("wrong shutdown timeout: got: %s, want: 2s", server.ShutdownTimeout)
+	}
+	if server.MaxClients != 10 || server.MaxRooms != 5 {
+		t.Errorf("wrong limits: got: %d/%d, want: 10/5", server.MaxClients, server.MaxRooms)
+	}
+	if server.RoomIdleTimeout != time.Minute {
+		t.Errorf("wrong room idle timeout: got: %s, want: 1m", server.RoomIdleTimeout)
+	}
+	if server.LobbyIdleTimeout != 2*time.Minute || server.GameIdleTimeout != 30*time.Second {
+		t.Errorf("wrong client idle timeouts: got: %s/%s, want: 2m/30s", server.LobbyIdleTimeout, server.GameIdleTimeout)
+	}
+	if server.ResumeTimeout != time.Minute {
+		t.Errorf("wrong resume timeout: got: %s, want: 1m", server.ResumeTimeout)
+	}
+	if server.ZombieCooldown != 2*time.Second {
+		t.Errorf("wrong zombie cooldown: got: %s, want: 2s", server.ZombieCooldown)
+	}
+	if server.ChatLimit != 3 || server.ChatInterval != 5*time.Second || server.ChatFilter == nil {
+		t.Errorf("wrong chat settings: got: %d/%s, filter set: %t", server.ChatLimit, server.ChatInterval, server.ChatFilter != nil)
+	}
+	if len(server.Rooms) != 2 {
+		t.Fatalf("wrong room count: got: %d, want: 2", len(server.Rooms))
+	}
+	if !server.Rooms[0].Default || server.DefaultRoom != server.Rooms[0].Room {
+		t.Errorf("first room should be default room")
+	}
+
+	wall, ok := server.Rooms[1].Room.(*rooms.TheWall)
+	if !ok {
+		t.Fatalf("wrong room type: got: %T, want: *rooms.TheWall", server.Rooms[1].Room)
+	}
+	if wall.Name() != "WALL1" {
+		t.Errorf("wrong room name: got: '%s', want: 'WALL1'", wall.Name())
+	}
+	if wall.Options.PlayerScore != 1000000 || wall.Options.CrawlerTick != time.Second {
+		t.Errorf("room options are not applied: got: %+v", wall.Options)
+	}
+	if len(server.Rooms[1].Zombies) != 4 {
+		t.Fatalf("wrong zombie count: got: %d, want: 4", len(server.Rooms[1].Zombies))
+	}
+	if armored, ok := server.Rooms[1].Zombies[3].(*zombies.Armored); !ok || armored.MaxHP != 5 {
+		t.Errorf("armored zombie hp is not applied: got: %+v", server.Rooms[1].Zombies[3])
+	}
+}
+
+func TestValidation(t *testing.T) {
+	testTable := []struct {
+		Config      string
+		ExpectedErr string
+	}{
+		{
+			Config:      `{"adr": ":3333"}`,
+			ExpectedErr: `config: unknown field "adr"`,
+		},
+		{
+			Config:      "{\n\"addr\": 3333\n}",
+			ExpectedErr: "config: addr: line 2: cannot use number as string",
+		},
+		{
+			Config:      "{\n\"addr\": \":3333\",\n}",
+			ExpectedErr: "config: line 3: invalid character '}' looking for beginning of object key string",
+		},
+		{
+			Config:      `{"shutdown_timeout": "soon"}`,
+			ExpectedErr: `config: shutdown_timeout: bad duration 'soon', expected e.g. "5s"`,
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "wall"}, {"name": "B", "type": "castle"}]}`,
+			ExpectedErr: "config: rooms[1].type: unknown room type 'castle', expected one of: training, wall",
+		},
+		{
+			Config:      `{"rooms": [{"type": "wall"}]}`,
+			ExpectedErr: "config: rooms[0].name: room name is required",
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "wall", "options": {"kills": 0}}]}`,
+			ExpectedErr: "config: rooms[0].options.kills: bad room option 'kills': must be at least 1",
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "wall", "options": {"breaches": 5, "kills": "abc"}}]}`,
+			ExpectedErr: "config: rooms[0].options.kills: bad room option 'kills': abc",
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "wall", "options": {"width": 1000000.5}}]}`,
+			ExpectedErr: "config: rooms[0].options.width: bad room option 'width': 1000000.5",
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "wall", "zombies": [{"type": "crawler", "count": "many"}]}]}`,
+			ExpectedErr: "config: rooms[0].zombies[0].count: line 1: cannot use string as int",
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "wall", "options": {"kills": [1]}}]}`,
+			ExpectedErr: "config: rooms[0].options.kills: must be a string, number or bool",
+		},
+		{
+			Config:      `{"default_room": {"type": "wall", "zombies": [{"type": "vampire"}]}}`,
+			ExpectedErr: "config: default_room.zombies[0].type: unknown zombie type 'vampire', expected one of: armored, boss, crawler, dummy, rabbit",
+		},
+		{
+			Config:      `{"default_room": {"type": "training", "zombies": [{"type": "crawler", "hp": 3}]}}`,
+			ExpectedErr: "config: default_room.zombies[0].hp: zombie type 'crawler' does not have armor",
+		},
+		{
+			Config:      `{"default_room": {"type": "training", "zombies": [{"type": "armored", "hp": -1}]}}`,
+			ExpectedErr: "config: default_room.zombies[0].hp: cannot be negative",
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "wall"}, {"name": "A", "type": "training"}]}`,
+			ExpectedErr: "config: rooms[1].name: room 'A' is already defined in rooms[0]",
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "wall"}, {"name": "a", "type": "training"}]}`,
+			ExpectedErr: "config: rooms[1].name: room 'a' is already defined in rooms[0]",
+		},
+		{
+			Config:      `{"default_room": {"type": "wall"}, "rooms": [{"name": "the-wall", "type": "training"}]}`,
+			ExpectedErr: "config: rooms[0].name: room 'the-wall' is already defined in default_room",
+		},
+		{
+			Config:      `{"rooms": [{"name": "A", "type": "training", "options": {"Color": "red"}}]}`,
+			ExpectedErr: "config: rooms[0].options.Color: bad room option 'color': unknown option",
+		},
+		{
+			Config:      `{"limits": {"max_rooms": 1}, "rooms": [{"name": "A", "type": "wall"}, {"name": "B", "type": "wall"}]}`,
+			ExpectedErr: "config: limits.max_rooms: 2 rooms are defined but only 1 are allowed",
+		},
+	}
+
+	for idx, c := range testTable {
+		cfg, err := config.Load(strings.NewReader(c.Config))
+		if err == nil {
+			_, err = cfg.NewServer()
+		}
+		if err == nil {
+			t.Errorf("expected error: case %d", idx)
+			continue
+		}
+		if err.Error() != c.ExpectedErr {
+			t.Errorf("wrong error: case %d, got: '%s', want: '%s'", idx, err, c.ExpectedErr)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/sheirys/zombebattle/engine"
 	"github.com/sheirys/zombebattle/engine/rooms"
 )
@@ -12,6 +18,17 @@ func main() {
 		DefaultRoom: &rooms.TheWall{},
 	}
 
-	server.Run()
+	// stop the server gracefully when process is interrupted.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+		<-stop
+		cancel()
+	}()
+
+	if err := server.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
 
 }
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+// zombieCommands are commands that can be used by players that play as
+// zombies.
+var zombieCommands = []types.Command{
+	{
+		Name: types.EventMove,
+		Args: []types.Arg{
+			{Name: "dx", Kind: types.ArgInt},
+			{Name: "dy", Kind: types.ArgInt},
+		},
+		Help:  "crawl one step, e.g. MOVE -1 0 to crawl to the wall",
+		Scope: types.ScopeRoom,
+	},
+}
+
+// asZombie will return true if `JOIN` arguments are `AS ZOMBIE`.
+func asZombie(args []string) bool {
+	return len(args) == 2 && strings.EqualFold(args[0], "AS") && strings.EqualFold(args[1], "ZOMBIE")
+}
+
+// hauntable will return true if players can join the room as zombies. Room
+// must implement types.Watchable, so zombie player receives room events, and
+// types.ZombieRemover, so zombie is removed when player leaves.
+func hauntable(room types.Room) bool {
+	_, watchable := room.(types.Watchable)
+	_, remover := room.(types.ZombieRemover)
+	return watchable && remover
+}
+
+// haunt will let client play in the room as zombies.Remote until client leaves
+// the room or disconnects. Client moves his zombie with `MOVE <dx> <dy>`
+// command and receives room events as spectator. Other commands that should be
+// handled by the room are rejected.
+func (s *Server) haunt(client *Client, room types.Room) error {
+	w := room.(types.Watchable)
+	zombie := &zombies.Remote{
+		Name:     client.Name(),
+		Cooldown: s.ZombieCooldown,
+	}
+
+	client.Notify("# you are zombie " + client.Name() + " in " + room.Name() + ", use MOVE <dx> <dy> to crawl, type LEAVE to return to the lobby\n")
+	s.setZombie(client, true)
+	w.AddSpectator(client)
+	room.AddZombie(zombie)
+	if snapshotter, ok := room.(types.Snapshotter); ok {
+		for _, e := range snapshotter.Snapshot() {
+			client.ProcessEvent(e)
+		}
+	}
+
+	err := client.Run(func(e types.Event) (bool, error) {
+		if e.Type == types.EventMove {
+			return true, zombie.Move(e.X, e.Y)
+		}
+		if handler, ok := s.handler(e.Type); ok {
+			return true, handler(client, e)
+		}
+		return true, types.ErrZombie
+	})
+	client.Detach()
+	room.(types.ZombieRemover).RemoveZombie(zombie)
+	w.RemoveSpectator(client)
+	s.setZombie(client, false)
+	return err
+}
+
+// setZombie will remember if client plays as zombie, so `WHO` can tell zombie
+// players from spectators.
+func (s *Server) setZombie(client *Client, zombie bool) {
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	if zombie {
+		s.zombies[client] = true
+		return
+	}
+	delete(s.zombies, client)
+}
+
+// haunted will return true if players play as zombies in the room.
+func (s *Server) haunted(room types.Room) bool {
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	for client, r := range s.clients {
+		if r == room && s.zombies[client] {
+			return true
+		}
+	}
+	return false
+}
+
+// isZombie will return true if player plays as zombie.
+func (s *Server) isZombie(player types.Player) bool {
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	return s.zombies[player]
+}
@@ -0,0 +1,102 @@
+package engine_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine"
+	"github.com/sheirys/zombebattle/engine/rooms"
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+func TestServerJoinAsZombie(t *testing.T) {
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:            10,
+			Height:           3,
+			PlayerScore:      5,
+			ZombieScore:      5,
+			CrawlerTick:      time.Hour,
+			ZombiesPerPlayer: 1,
+		},
+	}
+	server := &engine.Server{
+		Addr:           "127.0.0.1:0",
+		DefaultRoom:    room,
+		ZombieCooldown: time.Hour,
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	player, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer player.Close()
+	playerReader := bufio.NewReader(player)
+	player.Write([]byte("start alice\n"))
+	waitForLine(t, playerReader, "# THE-WALL")
+
+	zombie, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer zombie.Close()
+	reader := bufio.NewReader(zombie)
+	waitForLine(t, reader, "# new world.")
+
+	zombie.Write([]byte("join the-wall as vampire\n"))
+	waitForLine(t, reader, "# use JOIN <room> AS ZOMBIE")
+
+	zombie.Write([]byte("join the-wall as zombie\nstart bob\n"))
+	waitForLine(t, reader, "# selected room the-wall, you will play as zombie")
+	waitForLine(t, reader, "# you are zombie bob in THE-WALL")
+
+	// zombie appears on the right side of the map.
+	walk, err := types.ParseEvent(waitForLine(t, playerReader, "WALK remote-bob "))
+	if err != nil || walk.X != 9 {
+		t.Fatalf("unexpected zombie position: %+v, error: %v", walk, err)
+	}
+
+	zombie.Write([]byte("move -1 0\n"))
+	waitForLine(t, playerReader, fmt.Sprintf("WALK remote-bob 8 %d", walk.Y))
+
+	zombie.Write([]byte("move -1 0\n"))
+	waitForLine(t, reader, "ERR COOLDOWN")
+	zombie.Write([]byte("move 2 0\n"))
+	waitForLine(t, reader, "ERR OUT_OF_RANGE")
+	zombie.Write([]byte("shoot 1 1\n"))
+	waitForLine(t, reader, "ERR ZOMBIE")
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	lobby := bufio.NewReader(conn)
+	waitForLine(t, lobby, "# new world.")
+	conn.Write([]byte("who the-wall\n"))
+	waitForLine(t, lobby, "# players in THE-WALL: alice")
+	waitForLine(t, lobby, "# zombies in THE-WALL: bob")
+
+	// archers can shoot zombie player. He respawns on the right side.
+	player.Write([]byte(fmt.Sprintf("shoot 8 %d\n", walk.Y)))
+	waitForLine(t, reader, "BOOM alice 1 [remote-bob]")
+	waitForLine(t, reader, "WALK remote-bob 9 ")
+
+	zombie.Write([]byte("leave\n"))
+	waitForLine(t, reader, "# you left room THE-WALL")
+	if count := room.ZombieCount(); count != 1 {
+		t.Errorf("zombie player should be removed from the room: got %d zombies, want: 1", count)
+	}
+}
@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultClientQueueSize is used when client queue size is not set.
+	DefaultClientQueueSize = 256
+
+	// DefaultClientWriteTimeout is used when client write timeout is not
+	// set.
+	DefaultClientWriteTimeout = 10 * time.Second
+)
+
+// SlowClientPolicy defines what happens when client does not read messages
+// as fast as server sends them and outbound queue of the client is full.
+type SlowClientPolicy int
+
+// Possible slow client policies.
+const (
+	// DropWalks will drop the oldest queued WALK event to make room for new
+	// message. If there are no WALK events to drop, new WALK event is
+	// dropped and client is disconnected when other message does not fit.
+	DropWalks SlowClientPolicy = iota
+
+	// DisconnectSlow will disconnect client as soon as queue is full.
+	DisconnectSlow
+)
+
+// message is queued outbound message. Walk messages can be dropped when
+// client is too slow.
+type message struct {
+	data []byte
+	walk bool
+}
+
+// outbox holds bounded queue of messages that will be written to client
+// connection by single writer goroutine, so messages are written in the same
+// order as they were sent. Each connection has its own outbox.
+type outbox struct {
+	conn    net.Conn
+	queue   []message
+	dropped int
+	closing bool // flush queued messages and close connection.
+	closed  bool // connection is closed, new messages are ignored.
+	cond    *sync.Cond
+	mtx     sync.Mutex
+}
+
+// send will queue message for client.
+func (c *Client) send(data []byte, walk bool) {
+	o := c.outbound()
+
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if o.closing || o.closed {
+		return
+	}
+
+	if len(o.queue) >= c.queueSize() {
+		o.dropped++
+		switch {
+		case c.SlowClientPolicy == DropWalks && o.dropOldestWalk():
+		case c.SlowClientPolicy == DropWalks && walk:
+			// there is no older WALK to drop, so new one is
+			// dropped.
+			return
+		default:
+			log.Printf("client %s is too slow, disconnecting", c.id)
+			o.close()
+			return
+		}
+	}
+
+	o.queue = append(o.queue, message{data: data, walk: walk})
+	o.cond.Broadcast()
+}
+
+// dropOldestWalk will remove the oldest WALK message from the queue. False is
+// returned if there is no WALK message in the queue. Mutex must be held.
+func (o *outbox) dropOldestWalk() bool {
+	for i, m := range o.queue {
+		if m.walk {
+			o.queue = append(o.queue[:i], o.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// close will forget queued messages and close connection immediately. Mutex
+// must be held.
+func (o *outbox) close() {
+	o.queue = nil
+	o.closing = true
+	o.closed = true
+	o.conn.Close()
+	o.cond.Broadcast()
+}
+
+// hangUp will close client connection immediately. Messages sent after that
+// are ignored and not counted as dropped, e.g. while disconnected client waits
+// for resume.
+func (c *Client) hangUp() {
+	o := c.outbound()
+	o.mtx.Lock()
+	o.close()
+	o.mtx.Unlock()
+}
+
+// flushAndClose will close client connection when all queued messages are
+// written.
+func (c *Client) flushAndClose() {
+	o := c.outbound()
+	o.mtx.Lock()
+	o.closing = true
+	o.cond.Broadcast()
+	o.mtx.Unlock()
+}
+
+// Dropped will return how many messages were dropped because client was too
+// slow.
+func (c *Client) Dropped() int {
+	c.outMtx.Lock()
+	dropped := c.dropped
+	o := c.out
+	c.outMtx.Unlock()
+	if o == nil {
+		return dropped
+	}
+
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return dropped + o.dropped
+}
+
+// outbound will return outbox of current client connection. Outbox and its
+// writer goroutine are created with first message.
+func (c *Client) outbound() *outbox {
+	c.outMtx.Lock()
+	defer c.outMtx.Unlock()
+	if c.out == nil {
+		c.out = &outbox{conn: c.Conn}
+		c.out.cond = sync.NewCond(&c.out.mtx)
+		go c.writeLoop(c.out)
+	}
+	return c.out
+}
+
+// writeLoop will write queued messages to connection until client is dropped.
+// Messages are taken from the queue one by one, so slow client cannot hold
+// more than QueueSize messages and the one that is being written. Each write
+// must finish in WriteTimeout, otherwise client is disconnected.
+func (c *Client) writeLoop(o *outbox) {
+	for {
+		o.mtx.Lock()
+		for len(o.queue) == 0 && !o.closing {
+			o.cond.Wait()
+		}
+		if len(o.queue) == 0 {
+			// all queued messages are written.
+			o.mtx.Unlock()
+			o.conn.Close()
+			return
+		}
+		m := o.queue[0]
+		o.queue = o.queue[1:]
+		o.mtx.Unlock()
+
+		o.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout()))
+		if _, err := o.conn.Write(m.data); err != nil {
+			log.Printf("cannot write to client %s: %s", c.id, err)
+			o.mtx.Lock()
+			o.close()
+			o.mtx.Unlock()
+			return
+		}
+	}
+}
+
+func (c *Client) queueSize() int {
+	if c.QueueSize <= 0 {
+		return DefaultClientQueueSize
+	}
+	return c.QueueSize
+}
+
+func (c *Client) writeTimeout() time.Duration {
+	if c.WriteTimeout <= 0 {
+		return DefaultClientWriteTimeout
+	}
+	return c.WriteTimeout
+}
@@ -0,0 +1,165 @@
+package engine_test
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine"
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+// blockingConn blocks every write until it is released, so tests can
+// simulate slow client.
+type blockingConn struct {
+	net.Conn
+	writes  chan string
+	release chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newBlockingConn() *blockingConn {
+	return &blockingConn{
+		writes:  make(chan string, 100),
+		release: make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (c *blockingConn) Write(b []byte) (int, error) {
+	c.writes <- string(b)
+	select {
+	case <-c.release:
+	case <-c.closed:
+	}
+	return len(b), nil
+}
+
+func (c *blockingConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *blockingConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func walk(y int64) types.Event {
+	return types.Event{Type: types.EventWalk, Actor: "zombie", X: 1, Y: y}
+}
+
+func boom(points int) types.Event {
+	return types.Event{Type: types.EventBoom, Actor: "player", Points: points, Hits: []string{}}
+}
+
+func TestClientQueueOrder(t *testing.T) {
+	conn := newBlockingConn()
+	close(conn.release)
+	client := &engine.Client{Conn: conn}
+
+	expected := []types.Event{walk(1), boom(1), walk(2), walk(3), boom(2)}
+	for _, e := range expected {
+		client.ProcessEvent(e)
+	}
+	for idx, e := range expected {
+		select {
+		case line := <-conn.writes:
+			if line != e.String()+"\n" {
+				t.Errorf("wrong message order: case %d, got: '%s', want: '%s'", idx, line, e.String())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %d was not written", idx)
+		}
+	}
+	client.Drop()
+}
+
+func TestClientDropWalks(t *testing.T) {
+	conn := newBlockingConn()
+	client := &engine.Client{
+		Conn:      conn,
+		QueueSize: 2,
+	}
+
+	// first WALK is being written, so queue is empty.
+	client.ProcessEvent(walk(1))
+	<-conn.writes
+
+	client.ProcessEvent(walk(2))
+	client.ProcessEvent(boom(1))
+	// queue is full, so the oldest WALK is dropped.
+	client.ProcessEvent(walk(3))
+	client.ProcessEvent(boom(2))
+	if dropped := client.Dropped(); dropped != 2 {
+		t.Errorf("wrong dropped count: got: %d, want: 2", dropped)
+	}
+
+	// there are no WALK events to drop anymore, so new WALK is dropped.
+	client.ProcessEvent(walk(4))
+	if dropped := client.Dropped(); dropped != 3 {
+		t.Errorf("wrong dropped count: got: %d, want: 3", dropped)
+	}
+
+	// other messages do not fit, so client is disconnected.
+	client.Notify("# hello\n")
+	select {
+	case <-conn.closed:
+	case <-time.After(time.Second):
+		t.Errorf("slow client was not disconnected")
+	}
+}
+
+func TestClientQueueLimit(t *testing.T) {
+	conn := newBlockingConn()
+	client := &engine.Client{
+		Conn:      conn,
+		QueueSize: 2,
+	}
+
+	client.ProcessEvent(walk(1))
+	<-conn.writes
+	client.Notify("# one\n")
+	client.Notify("# two\n")
+
+	// when first WALK is written only one message is taken from the
+	// queue, so queue has room for one message only.
+	conn.release <- struct{}{}
+	<-conn.writes
+	client.Notify("# three\n")
+	select {
+	case <-conn.closed:
+		t.Fatalf("client was disconnected while queue was not full")
+	default:
+	}
+	client.Notify("# four\n")
+	select {
+	case <-conn.closed:
+	case <-time.After(time.Second):
+		t.Errorf("slow client was not disconnected")
+	}
+}
+
+func TestClientDisconnectSlow(t *testing.T) {
+	conn := newBlockingConn()
+	client := &engine.Client{
+		Conn:             conn,
+		QueueSize:        1,
+		SlowClientPolicy: engine.DisconnectSlow,
+	}
+
+	client.ProcessEvent(walk(1))
+	<-conn.writes
+	client.ProcessEvent(walk(2))
+	client.ProcessEvent(walk(3))
+
+	select {
+	case <-conn.closed:
+	case <-time.After(time.Second):
+		t.Errorf("slow client was not disconnected")
+	}
+	if dropped := client.Dropped(); dropped != 1 {
+		t.Errorf("wrong dropped count: got: %d, want: 1", dropped)
+	}
+}
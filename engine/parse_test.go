@@ -1,6 +1,7 @@
 package engine_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/sheirys/zombebattle/engine"
@@ -17,7 +18,7 @@ func TestParse(t *testing.T) {
 			Input: []byte("start jonas"),
 			ExpectedEvent: types.Event{
 				Type:  types.EventStart,
-				Actor: "JONAS",
+				Actor: "jonas",
 			},
 			ExpectedErr: nil,
 		},
@@ -40,11 +41,21 @@ func TestParse(t *testing.T) {
 			ExpectedEvent: types.Event{},
 			ExpectedErr:   engine.ErrBadInput,
 		},
+		{
+			Input:         []byte("shoot -1 2"),
+			ExpectedEvent: types.Event{},
+			ExpectedErr:   engine.ErrBadInput,
+		},
+		{
+			Input:         []byte("shoot 1"),
+			ExpectedEvent: types.Event{},
+			ExpectedErr:   engine.ErrBadInput,
+		},
 		{
 			Input: []byte("join castle1"),
 			ExpectedEvent: types.Event{
 				Type:  types.EventJoin,
-				Actor: "CASTLE1",
+				Actor: "castle1",
 			},
 			ExpectedErr: nil,
 		},
@@ -52,10 +63,96 @@ func TestParse(t *testing.T) {
 			Input: []byte("new castle1"),
 			ExpectedEvent: types.Event{
 				Type:  types.EventNew,
-				Actor: "CASTLE1",
+				Actor: "castle1",
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input: []byte("new castle1 wall width=20"),
+			ExpectedEvent: types.Event{
+				Type:  types.EventNew,
+				Actor: "castle1",
+				Args:  []string{"wall", "width=20"},
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input: []byte("Start VanaGas"),
+			ExpectedEvent: types.Event{
+				Type:  types.EventStart,
+				Actor: "VanaGas",
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input: []byte("rematch"),
+			ExpectedEvent: types.Event{
+				Type: types.EventRematch,
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input: []byte("restart\r\n"),
+			ExpectedEvent: types.Event{
+				Type: types.EventRematch,
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input: []byte("leave"),
+			ExpectedEvent: types.Event{
+				Type: types.EventLeave,
 			},
 			ExpectedErr: nil,
 		},
+		{
+			Input: []byte("list"),
+			ExpectedEvent: types.Event{
+				Type: types.EventList,
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input: []byte("who woods"),
+			ExpectedEvent: types.Event{
+				Type:  types.EventWho,
+				Actor: "woods",
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input: []byte("info woods"),
+			ExpectedEvent: types.Event{
+				Type:  types.EventInfo,
+				Actor: "woods",
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input:         []byte("who"),
+			ExpectedEvent: types.Event{},
+			ExpectedErr:   engine.ErrBadInput,
+		},
+		{
+			Input: []byte("ping 42"),
+			ExpectedEvent: types.Event{
+				Type: types.EventPing,
+				Args: []string{"42"},
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input: []byte("help"),
+			ExpectedEvent: types.Event{
+				Type: types.EventHelp,
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Input:         []byte("start"),
+			ExpectedEvent: types.Event{},
+			ExpectedErr:   engine.ErrBadInput,
+		},
 		{
 			Input:         []byte("fat mama"),
 			ExpectedEvent: types.Event{},
@@ -82,6 +179,9 @@ func TestParse(t *testing.T) {
 		if event.Actor != v.ExpectedEvent.Actor {
 			t.Errorf("incorrect event actor. case: %d got: %s want: %s", i, event.Actor, v.ExpectedEvent.Actor)
 		}
+		if !reflect.DeepEqual(event.Args, v.ExpectedEvent.Args) && len(event.Args)+len(v.ExpectedEvent.Args) > 0 {
+			t.Errorf("incorrect event args. case: %d got: %v want: %v", i, event.Args, v.ExpectedEvent.Args)
+		}
 		if err != v.ExpectedErr {
 			t.Errorf("incorrect error. case: %d got: %v want: %v", i, err, v.ExpectedErr)
 		}
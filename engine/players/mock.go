@@ -2,29 +2,55 @@ package players
 
 import (
 	"log"
+	"sync"
 
 	"github.com/sheirys/zombebattle/engine/types"
 )
 
 // MockPlayer satisfies engine.player interface and can be used in tests.
 type MockPlayer struct {
-	Events chan types.Event
+	SessionID string
+	Nick      string
+	Events    chan types.Event
+
+	processed []types.Event
+	mtx       sync.Mutex
+}
+
+// ID will return session ID of this mock client.
+func (m *MockPlayer) ID() string {
+	return m.SessionID
+}
+
+// Name will return name of this mock client.
+func (m *MockPlayer) Name() string {
+	return m.Nick
 }
 
 // Notify will print notfy message for client - in this case in log console.
 func (m *MockPlayer) Notify(msg string) {
-	log.Printf(msg + "\n")
+	log.Print(msg)
+}
+
+// EventStream will return events produced by this mock client.
+func (m *MockPlayer) EventStream() <-chan types.Event {
+	return m.Events
 }
 
-// GetEvent will return event produced by this mock client.
-func (m *MockPlayer) GetEvent() (types.Event, bool) {
-	event, ok := <-m.Events
-	return event, ok
+// ProcessEvent will remember event passed by room, so tests can check it
+// with Processed.
+func (m *MockPlayer) ProcessEvent(e types.Event) {
+	m.mtx.Lock()
+	m.processed = append(m.processed, e)
+	m.mtx.Unlock()
 }
 
-// ProcessEvent will handle event.
-// FIXME: implement this.
-func (m *MockPlayer) ProcessEvent(e types.Event) {}
+// Processed will return events passed to this mock client by room.
+func (m *MockPlayer) Processed() []types.Event {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return append([]types.Event(nil), m.processed...)
+}
 
 // ProduceEvent will add event into mock client event stream.
 func (m *MockPlayer) ProduceEvent(e types.Event) {
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+// DefaultMaxLineLength is used when client max line length is not set.
+const DefaultMaxLineLength = 512
+
+// telnet protocol bytes, see RFC 854.
+const (
+	telnetSE   = 240 // end of subnegotiation
+	telnetSB   = 250 // start of subnegotiation
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255 // interpret as command
+)
+
+// LineReader reads lines from client connection. One LineReader should be
+// used for the whole connection, so commands that client sends in one write
+// are not lost. Line endings `\n` and `\r\n` are accepted and telnet command
+// sequences are stripped from input.
+type LineReader struct {
+	r   *bufio.Reader
+	max int
+}
+
+// NewLineReader will create line reader. Lines longer than max bytes are
+// rejected with types.ErrLineTooLong. If max is not positive,
+// DefaultMaxLineLength is used.
+func NewLineReader(r io.Reader, max int) *LineReader {
+	if max <= 0 {
+		max = DefaultMaxLineLength
+	}
+	return &LineReader{
+		r:   bufio.NewReader(r),
+		max: max,
+	}
+}
+
+// ReadLine will return next line without line ending. If line is too long,
+// rest of the line is discarded and types.ErrLineTooLong is returned, so
+// reader can be used for next line. Other errors are returned from underlying
+// reader.
+func (l *LineReader) ReadLine() ([]byte, error) {
+	var line []byte
+	tooLong := false
+	for {
+		b, err := l.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch b {
+		case '\n':
+			if n := len(line); n > 0 && line[n-1] == '\r' {
+				line = line[:n-1]
+			}
+			if tooLong || len(line) > l.max {
+				return nil, types.ErrLineTooLong
+			}
+			return line, nil
+		case telnetIAC:
+			if err := l.skipCommand(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// one byte more than max is kept, so `\r` of line ending is
+		// not counted as part of the line.
+		if len(line) > l.max {
+			tooLong = true
+			continue
+		}
+		line = append(line, b)
+	}
+}
+
+// skipCommand will skip telnet command that follows IAC byte.
+func (l *LineReader) skipCommand() error {
+	cmd, err := l.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	switch cmd {
+	case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+		// option negotiation has one option byte.
+		_, err = l.r.ReadByte()
+	case telnetSB:
+		// skip subnegotiation until IAC SE.
+		var prev byte
+		for {
+			b, err := l.r.ReadByte()
+			if err != nil {
+				return err
+			}
+			if prev == telnetIAC && b == telnetSE {
+				return nil
+			}
+			prev = b
+		}
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package engine_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sheirys/zombebattle/engine"
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+func TestLineReader(t *testing.T) {
+	input := "start bob\n" +
+		"shoot 1 1\r\n" +
+		"\xff\xfb\x01jo\xff\xf1in x\n" + // IAC WILL ECHO and IAC NOP
+		"\xff\xfa\x18\x00xterm\xff\xf0list\n" + // terminal type subnegotiation
+		strings.Repeat("a", 20) + "\n" +
+		strings.Repeat("b", 16) + "\r\n" +
+		strings.Repeat("c", 17) + "\n" +
+		"\n" +
+		"leave"
+
+	testTable := []struct {
+		Line  string
+		Error error
+	}{
+		{Line: "start bob"},
+		{Line: "shoot 1 1"},
+		{Line: "join x"},
+		{Line: "list"},
+		{Error: types.ErrLineTooLong},
+		{Line: strings.Repeat("b", 16)},
+		{Error: types.ErrLineTooLong},
+		{Line: ""},
+		{Error: io.EOF},
+	}
+
+	reader := engine.NewLineReader(strings.NewReader(input), 16)
+	for i, v := range testTable {
+		line, err := reader.ReadLine()
+		if err != v.Error {
+			t.Errorf("%d: expected error '%v', got '%v'", i, v.Error, err)
+			continue
+		}
+		if string(line) != v.Line {
+			t.Errorf("%d: expected line '%s', got '%s'", i, v.Line, line)
+		}
+	}
+}
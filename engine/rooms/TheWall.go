@@ -2,45 +2,97 @@ package rooms
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"log"
+	"math"
+	"sort"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/sheirys/zombebattle/engine/types"
 	"github.com/sheirys/zombebattle/engine/zombies"
 )
 
-// General settings for this room.
+// Default settings for this room. These can be changed with TheWallOptions.
 const (
-	TheWallMapWidth       = 29 // map size 10x30 but we count from 0 here.
-	TheWallMapHeight      = 9  // map size
-	TheWallMaxPlayerScore = 5  // zombies needs to kill before victory
-	TheWallMaxZombieScore = 5  // zombies reach the wall before game over
+	TheWallMapWidth       = 29         // map size 10x30 but we count from 0 here.
+	TheWallMapHeight      = 9          // map size
+	TheWallMaxPlayerScore = 5          // zombies needs to kill before victory
+	TheWallMaxZombieScore = 5          // zombies reach the wall before game over
+	TheWallMaxMinions     = 10         // minions bosses can have at the same time
+	TheWallName           = "THE-WALL" // room name if name is not set
 )
 
 // TheWall satisfies engine.Room interface and can be used as playable room.
 // This room has a wall on X0 axis where zombies tries to reach it from right
-// side. This room should use only zoombies.Crawler type zombies. When zombie
-// reaches the wall, room should kill him, and respawn into random position.
+// side. Room spawns zombies.Crawler and zombies.Boss zombies, but any zombie
+// can be added with AddZombie, e.g. zombies.Remote controlled by player. When
+// zombie reaches the wall, room respawns him into random position.
 // By default, this room does not have predefined zombies. If server requires it
 // then these zombies will be spawned. We will spawn a new zombie each time when
-// player joins this room.
+// player joins this room. Room rules can be changed with Options. If Options
+// are left empty, DefaultTheWallOptions will be used. When game is over players
+// stay in the room and can vote for a rematch with `REMATCH` command. Room can
+// be watched by spectators, they do not spawn crawlers.
 type TheWall struct {
 	Zombies      []types.Zombie
+	Options      TheWallOptions
 	players      []types.Player
-	playerEvents chan types.Event
+	playerEvents chan playerEvent
 	zombieEvents chan types.Event
+	restart      chan chan struct{} // new round requests, see Restart.
+	restartTimer *time.Timer
+	votes        map[types.Player]bool
+	stats        map[types.Player]*TheWallStats
+	minions      map[types.Zombie]bool
 	name         string
+	spectators
 
 	// room settings
-	width, height int64 // map size
-	playerScore   int64 // how many zombies must be killed before win?
-	zombieScore   int64 // how many times wall can be reached by zombies?
+	width, height int64 // max coordinates in this map
+	playerScore   int64 // how many zombies are killed by players?
+	zombieScore   int64 // how many times wall was reached by zombies?
+
+	// room systems. Zombies live only for one round, so they are
+	// summoned with round context.
+	ctx       context.Context
+	stopFunc  context.CancelFunc
+	round     context.Context
+	stopRound context.CancelFunc
+	running   bool
+	started   bool
+	mtx       sync.Mutex
+	wg        sync.WaitGroup
+}
+
+// TheWallStats holds statistics of one player in current round. Shots counts
+// how many times player has shot, Hits counts shots that hit at least one
+// zombie. Breaches counts how many times zombies reached the wall while player
+// was in the room.
+type TheWallStats struct {
+	Kills    int
+	Shots    int
+	Hits     int
+	Breaches int
+}
+
+// Accuracy will return part of shots that hit a zombie in percents.
+func (s TheWallStats) Accuracy() int {
+	if s.Shots == 0 {
+		return 0
+	}
+	return s.Hits * 100 / s.Shots
+}
 
-	// room systems
-	ctx      context.Context
-	stopFunc context.CancelFunc
-	running  bool
+// NewTheWall will create TheWall room. This satisfies types.RoomFactory so it
+// can be registered as room type in server.
+func NewTheWall(opts types.RoomOptions) (types.Room, error) {
+	options, err := ParseTheWallOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &TheWall{Options: options, name: TheWallName}, nil
 }
 
 // Name will return room name.
@@ -54,77 +106,149 @@ func (p *TheWall) SetName(n string) {
 }
 
 // AddPlayer will attach client to this room. Everytime when we attach new
-// player, new crawler will be spawned.
+// player, Options.ZombiesPerPlayer new crawlers will be spawned.
 func (p *TheWall) AddPlayer(player types.Player) error {
+	p.mtx.Lock()
 	p.players = append(p.players, player)
+	p.stats[player] = &TheWallStats{}
+	p.started = true
+	p.mtx.Unlock()
 	player.Notify(p.hello())
+	p.sendEventToPlayers(types.Event{Type: types.EventJoined, Actor: player.Name()})
 
 	// check scores. Maybe this room is already in end state.
 	p.checkScores()
 
 	// add zombies only then, when we do not have a winner of this room.
-	if p.running {
-		crawler := &zombies.Crawler{}
-		p.AddZombie(crawler)
+	if p.isRunning() {
+		p.spawnCrawlers(p.Options.ZombiesPerPlayer)
 	}
 
+	events := player.EventStream()
 	go func() {
-		for {
-			// handle player events
-			if event, open := player.GetEvent(); open && p.running {
-				p.playerEvents <- event
+		// stream is read until it is closed, so client never blocks
+		// on it. Server removes player from the room when he leaves
+		// or disconnects.
+		for event := range events {
+			// player could leave the room while we were waiting
+			// for event.
+			if !p.hasPlayer(player) {
 				continue
 			}
-			return
+			select {
+			case p.playerEvents <- playerEvent{player: player, event: event}:
+			case <-p.ctx.Done():
+			}
 		}
 	}()
 	return nil
 }
 
+// RemovePlayer will detach player from this room. Votes for a rematch and
+// statistics of this player are forgotten.
+func (p *TheWall) RemovePlayer(player types.Player) error {
+	p.mtx.Lock()
+	delete(p.votes, player)
+	delete(p.stats, player)
+	for i, pl := range p.players {
+		if pl == player {
+			p.players = append(p.players[:i], p.players[i+1:]...)
+			p.mtx.Unlock()
+			p.sendEventToPlayers(types.Event{Type: types.EventLeft, Actor: player.Name()})
+			return nil
+		}
+	}
+	p.mtx.Unlock()
+	return ErrPlayerNotFound
+}
+
 // AddZombie will attach zombie to this room.
 func (p *TheWall) AddZombie(z types.Zombie) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
 	z.Reset(p.width, zombies.RandomPos(0, p.height))
 	p.Zombies = append(p.Zombies, z)
-	z.Summon(p.ctx, p.zombieEvents)
+	z.Summon(p.round, p.zombieEvents, p.bounds())
 	z.Run()
 	return nil
 }
 
-// Init will do some room preparations.
+// RemoveZombie will kill zombie and remove it from this room. This is used
+// when player that plays as zombie leaves the room.
+func (p *TheWall) RemoveZombie(z types.Zombie) error {
+	p.mtx.Lock()
+	var err error
+	p.Zombies, err = removeZombie(p.Zombies, z)
+	delete(p.minions, z)
+	p.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+	return z.Kill()
+}
+
+// Init will do some room preparations. Error is returned if room Options are
+// not valid.
 func (p *TheWall) Init() error {
 	if p.name == "" {
-		p.name = "THE-WALL"
+		p.name = TheWallName
+	}
+	if p.Options == (TheWallOptions{}) {
+		p.Options = DefaultTheWallOptions()
+	}
+	if err := p.Options.Validate(); err != nil {
+		return err
 	}
 	p.zombieEvents = make(chan types.Event, 1)
-	p.playerEvents = make(chan types.Event, 1)
+	p.playerEvents = make(chan playerEvent, 1)
+	p.restart = make(chan chan struct{})
+	p.votes = map[types.Player]bool{}
+	p.stats = map[types.Player]*TheWallStats{}
+	p.minions = map[types.Zombie]bool{}
 	p.ctx, p.stopFunc = context.WithCancel(context.Background())
+	p.round, p.stopRound = context.WithCancel(p.ctx)
 
-	p.width = TheWallMapWidth
-	p.height = TheWallMapHeight
+	p.width = p.Options.Width - 1
+	p.height = p.Options.Height - 1
 	p.running = true
 
 	// summon all pre-defined zombies.
+	p.mtx.Lock()
 	for _, zombie := range p.Zombies {
 		zombie.Reset(p.width, zombies.RandomPos(0, p.height))
-		zombie.Summon(p.ctx, p.zombieEvents)
+		zombie.Summon(p.round, p.zombieEvents, p.bounds())
 		zombie.Run()
 	}
+	p.mtx.Unlock()
+
+	p.spawnCrawlers(p.Options.InitialZombies)
+	p.spawnBosses(p.Options.Bosses)
 	return nil
 }
 
-// Stop stops this room and kills all zombies.
+// Stop stops this room and kills all zombies. Stop will block until room and
+// all zombies inside it are stopped.
 func (p *TheWall) Stop() error {
-	defer func() {
-		close(p.zombieEvents)
-		close(p.playerEvents)
-	}()
+	if p.stopFunc == nil {
+		return nil
+	}
 	p.stopFunc()
+	p.mtx.Lock()
+	p.running = false
+	zombies := p.Zombies
+	p.mtx.Unlock()
+	for _, zombie := range zombies {
+		zombie.Kill()
+	}
+	p.wg.Wait()
 	return nil
 }
 
 // Run will initialize this room.
 func (p *TheWall) Run() error {
+	p.wg.Add(1)
 	go func() {
+		defer p.wg.Done()
 		for {
 			if err := p.Process(); err != nil {
 				return
@@ -134,46 +258,182 @@ func (p *TheWall) Run() error {
 	return nil
 }
 
+// Process will handle one queued room event. ErrRoomStopped is returned when
+// room is stopped.
 func (p *TheWall) Process() error {
+	if p.ctx.Err() != nil {
+		return ErrRoomStopped
+	}
 
 	select {
+	// react when room is stopped
+	case <-p.ctx.Done():
+		return ErrRoomStopped
 	// handle player event
-	case playerEvent, ok := <-p.playerEvents:
-		if !ok {
-			// react when channel is closed
-			return errors.New("player channel closed")
-		}
-		if playerEvent.Type == types.EventShoot {
-			// return shot result to players
-			booms := p.processShootEvent(playerEvent)
-			p.sendEventToPlayers(booms)
+	case e := <-p.playerEvents:
+		switch {
+		case e.event.Type == types.EventShoot && !p.onMap(e.event.X, e.event.Y):
+			e.player.ProcessEvent(types.ErrOutOfRange.Event())
+		case e.event.Type == types.EventShoot && p.isRunning():
+			// shot result is sent to players while processing.
+			p.processShootEvent(e)
+		case e.event.Type == types.EventScore:
+			e.player.Notify(p.scoreboard())
+		case e.event.Type == types.EventRematch:
+			p.processRematchEvent(e.player)
 		}
 	// handle zombie event
-	case zombieEvent, ok := <-p.zombieEvents:
-		if !ok {
-			// reach when channel is closed
-			return errors.New("zombie channel closed")
+	case zombieEvent := <-p.zombieEvents:
+		// zombies can still have queued moves when game is over.
+		if !p.isRunning() {
+			return nil
+		}
+		switch zombieEvent.Type {
+		case types.EventSpawn:
+			p.processSpawnEvent(zombieEvent)
+		case types.EventWalk:
+			// check maybe zombie reached the wall?
+			p.processMoveEvent(zombieEvent)
+			p.sendEventToPlayers(zombieEvent)
+		default:
+			p.sendEventToPlayers(zombieEvent)
+		}
+	// countdown for automatic restart is over or new round was requested
+	// with Restart.
+	case done := <-p.restart:
+		p.restartGame()
+		if done != nil {
+			close(done)
 		}
-		// check maybe zombie reached the wall?
-		p.processMoveEvent(zombieEvent)
-		p.sendEventToPlayers(zombieEvent)
 	}
 	return nil
 }
 
 // ZombiesWon will return true if zombies won this room.
 func (p *TheWall) ZombiesWon() bool {
-	return p.getZombieScores() >= TheWallMaxPlayerScore
+	return p.getZombieScores() >= p.Options.ZombieScore
 }
 
 // PlayersWon will return true if players won this room.
 func (p *TheWall) PlayersWon() bool {
-	return p.getPlayerScores() >= TheWallMaxPlayerScore
+	return p.getPlayerScores() >= p.Options.PlayerScore
+}
+
+// State will return current state of this room. Room is waiting until first
+// player joins and is finished when game is over.
+func (p *TheWall) State() types.RoomState {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	switch {
+	case !p.running:
+		return types.RoomFinished
+	case p.started:
+		return types.RoomRunning
+	}
+	return types.RoomWaiting
+}
+
+// Players will return players that are currently in this room.
+func (p *TheWall) Players() []types.Player {
+	return p.playerList()
+}
+
+// ZombieCount will return how many zombies are living in this room.
+func (p *TheWall) ZombieCount() int {
+	return len(p.zombieList())
+}
+
+// Scores will return scores of players and zombies in current round.
+func (p *TheWall) Scores() []types.Score {
+	return []types.Score{
+		{Team: "players", Points: p.getPlayerScores(), Max: p.Options.PlayerScore},
+		{Team: "zombies", Points: p.getZombieScores(), Max: p.Options.ZombieScore},
+	}
 }
 
+// Rules will describe rules of this room.
+func (p *TheWall) Rules() string {
+	return p.Options.String()
+}
+
+// Snapshot will describe current state of this room for player that resumes
+// session. WALK event is produced for each zombie if game is running and
+// SCORE event for both teams.
+func (p *TheWall) Snapshot() []types.Event {
+	var events []types.Event
+	if p.isRunning() {
+		for _, zombie := range p.zombieList() {
+			events = append(events, walkEvent(zombie))
+			if boss, ok := zombie.(*zombies.Boss); ok {
+				events = append(events, boss.Announcement())
+			}
+		}
+	}
+	for _, score := range p.Scores() {
+		events = append(events, types.Event{
+			Type:   types.EventScore,
+			Actor:  score.Team,
+			Points: int(score.Points),
+			Max:    int(score.Max),
+		})
+	}
+	return events
+}
+
+// spawnCrawlers will add count new crawlers into this room.
+func (p *TheWall) spawnCrawlers(count int) {
+	for i := 0; i < count; i++ {
+		p.AddZombie(&zombies.Crawler{Tick: p.Options.CrawlerTick})
+	}
+}
+
+// spawnBosses will add count new bosses into this room.
+func (p *TheWall) spawnBosses(count int) {
+	for i := 0; i < count; i++ {
+		p.AddZombie(&zombies.Boss{Tick: p.Options.CrawlerTick})
+	}
+}
+
+// processSpawnEvent will honour spawn request of zombie e.g. boss that calls
+// his minions. Minion of requested type is spawned at requested position.
+// Minions live until the end of the round and only TheWallMaxMinions of them
+// can live at the same time.
+func (p *TheWall) processSpawnEvent(e types.Event) {
+	if len(e.Args) == 0 {
+		return
+	}
+	p.mtx.Lock()
+	count := len(p.minions)
+	p.mtx.Unlock()
+	if count >= TheWallMaxMinions {
+		log.Printf("zombie %s cannot call more minions", e.Actor)
+		return
+	}
+
+	minion, err := zombies.New(e.Args[0])
+	if err != nil {
+		log.Printf("zombie %s cannot call minion: %s", e.Actor, err)
+		return
+	}
+	if crawler, ok := minion.(*zombies.Crawler); ok {
+		crawler.Tick = p.Options.CrawlerTick
+	}
+	p.AddZombie(minion)
+	if p.onMap(e.X, e.Y) {
+		minion.Reset(e.X, e.Y)
+	}
+	p.mtx.Lock()
+	p.minions[minion] = true
+	p.mtx.Unlock()
+	log.Printf("zombie %s called minion %s", e.Actor, minion.GetName())
+}
+
+// sendEventToPlayers will send event to all players and spectators in this
+// room. Players queue events, so events arrive in the same order as they were
+// sent.
 func (p *TheWall) sendEventToPlayers(e types.Event) {
-	for _, player := range p.players {
-		go player.ProcessEvent(e)
+	for _, player := range append(p.playerList(), p.Spectators()...) {
+		player.ProcessEvent(e)
 	}
 }
 
@@ -182,10 +442,16 @@ func (p *TheWall) sendEventToPlayers(e types.Event) {
 // zombie team and respawn it on the left.
 func (p *TheWall) processMoveEvent(e types.Event) {
 	if e.X == 0 {
+		p.mtx.Lock()
+		for _, stats := range p.stats {
+			stats.Breaches++
+		}
+		p.mtx.Unlock()
 		p.incZombieScores()
+		p.sendScore(1)
 		p.checkScores()
 		log.Printf("zombie %s reached the wall", e.Actor)
-		for _, zombie := range p.Zombies {
+		for _, zombie := range p.zombieList() {
 			if zombie.GetName() == e.Actor {
 				zombie.Reset(p.width, zombies.RandomPos(0, p.height))
 				break
@@ -195,62 +461,289 @@ func (p *TheWall) processMoveEvent(e types.Event) {
 }
 
 // processShootEvent will handle shoot event from player. Here we will check
-// each zombie position and check if any zombies are hit. In the end we will
-// produce BOOM event here wit points count and hit zombies.
-func (p *TheWall) processShootEvent(e types.Event) types.Event {
+// each zombie position and check if any zombies are hit. BOOM event with hit
+// zombies and points of the shooter is sent to players before scores are
+// updated. Points are how many zombies shooter has killed in this round.
+// Zombies that report health are listed with health left after hit.
+func (p *TheWall) processShootEvent(e playerEvent) {
 	hits := []string{}
-	for _, zombie := range p.Zombies {
+	kills := 0
+	for _, zombie := range p.zombieList() {
 		x, y := zombie.GetPos()
-		if x == e.X && y == e.Y {
-			hits = append(hits, zombie.GetName())
-			if zombie.Hit() {
+		if x == e.event.X && y == e.event.Y {
+			killed := zombie.Hit()
+			hits = append(hits, hitName(zombie))
+			if killed {
 				zombie.Reset(p.width, zombies.RandomPos(0, p.height))
-				p.incPlayerScores()
-				p.checkScores()
+				kills++
 			}
 		}
 	}
-	shootResult := types.Event{
+
+	// update statistics before checking scores, so final scoreboard
+	// includes this shot.
+	points := 0
+	p.mtx.Lock()
+	if stats, ok := p.stats[e.player]; ok {
+		stats.Shots++
+		if len(hits) > 0 {
+			stats.Hits++
+		}
+		stats.Kills += kills
+		points = stats.Kills
+	}
+	p.mtx.Unlock()
+
+	p.sendEventToPlayers(types.Event{
 		Type:   types.EventBoom,
-		Actor:  e.Actor,
-		Points: len(hits),
+		Actor:  e.event.Actor,
+		Points: points,
 		Hits:   hits,
+	})
+
+	for i := 0; i < kills; i++ {
+		p.incPlayerScores()
+		p.sendScore(0)
+		p.checkScores()
 	}
-	return shootResult
 }
 
 // checkScores should be called everytime when player hits a zombie or zombie
 // reaches the wall. Here we will check how many scores has zombies vs players
-// and decide if we need to continue this room, or someone wins.
-// FIXME: implement this.
+// and decide if we need to continue this room, or someone wins. When one team
+// wins, round is ended with endGame.
 func (p *TheWall) checkScores() {
 	log.Printf("scores for map %s", p.name)
-	log.Printf("zombies has %d/%d points", p.zombieScore, TheWallMaxZombieScore)
-	log.Printf("players has %d/%d points", p.playerScore, TheWallMaxPlayerScore)
+	log.Printf("zombies has %d/%d points", p.getZombieScores(), p.Options.ZombieScore)
+	log.Printf("players has %d/%d points", p.getPlayerScores(), p.Options.PlayerScore)
 
 	if p.ZombiesWon() {
-		p.endGame("zombies win")
+		p.endGame("zombies", fmt.Sprintf("wall breached %d times", p.Options.ZombieScore))
 		return
 	}
 
 	if p.PlayersWon() {
-		p.endGame("players win")
+		p.endGame("players", fmt.Sprintf("%d zombies killed", p.Options.PlayerScore))
 		return
 	}
 }
 
-// endGame will end this room. We will notify each player about winners of this
-// room, drop connections and stop all zombies in this room.
-func (p *TheWall) endGame(reason string) {
-	for _, player := range p.players {
-		player.Notify("# " + reason + "\n")
-		player.Drop()
+// endGame will end this round. We will send GAMEOVER event with winner team
+// and reason to each player and stop all zombies in this room. Players stay
+// in the room and can vote for a rematch. If Options.AutoRestart is set, new
+// round will start after countdown.
+func (p *TheWall) endGame(winner, reason string) {
+	// endGame is called from room event loop, so we cannot wait here
+	// until zombies stop. Just cancel the round and let zombies exit.
+	p.mtx.Lock()
+	if !p.running {
+		p.mtx.Unlock()
+		return
 	}
+	p.stopRound()
+	p.running = false
+	p.votes = map[types.Player]bool{}
+	p.mtx.Unlock()
+
+	p.sendEventToPlayers(types.Event{
+		Type:  types.EventGameOver,
+		Actor: winner,
+		Args:  []string{reason},
+	})
+
+	msg := p.scoreboard()
+	msg += "# " + winner + " win, " + reason + "\n"
+	msg += "# type REMATCH to vote for another round\n"
+	if p.Options.AutoRestart > 0 {
+		msg += fmt.Sprintf("# new round starts in %s\n", p.Options.AutoRestart)
+		p.mtx.Lock()
+		p.restartTimer = time.AfterFunc(p.Options.AutoRestart, func() {
+			select {
+			case p.restart <- nil:
+			case <-p.ctx.Done():
+			}
+		})
+		p.mtx.Unlock()
+	}
+	p.notifyPlayers(msg)
+}
+
+// processRematchEvent will register player vote for a rematch. New round
+// starts when Options.RematchQuorum of players in this room voted.
+func (p *TheWall) processRematchEvent(player types.Player) {
+	p.mtx.Lock()
 	if p.running {
-		p.Stop()
-		p.running = false
+		p.mtx.Unlock()
+		player.Notify("# game is still running\n")
+		return
+	}
+	p.votes[player] = true
+
+	// count only votes of players that are still in this room.
+	votes := 0
+	for _, pl := range p.players {
+		if p.votes[pl] {
+			votes++
+		}
+	}
+	needed := int(math.Ceil(p.Options.RematchQuorum * float64(len(p.players))))
+	if needed < 1 {
+		needed = 1
+	}
+	p.mtx.Unlock()
+
+	p.notifyPlayers(fmt.Sprintf("# rematch votes %d/%d\n", votes, needed))
+	if votes >= needed {
+		p.restartGame()
+	}
+}
+
+// restartGame will reset scores, respawn zombies and start new round in this
+// room. Nothing happens if game is still running. restartGame must be called
+// only from room event loop.
+func (p *TheWall) restartGame() {
+	p.mtx.Lock()
+	if p.running || p.ctx.Err() != nil {
+		p.mtx.Unlock()
+		return
+	}
+	if p.restartTimer != nil {
+		p.restartTimer.Stop()
+		p.restartTimer = nil
+	}
+	zombieList := append([]types.Zombie(nil), p.Zombies...)
+	p.mtx.Unlock()
+
+	// wait until zombies from previous round are dead.
+	for _, zombie := range zombieList {
+		zombie.Kill()
+	}
+
+	atomic.StoreInt64(&p.playerScore, 0)
+	atomic.StoreInt64(&p.zombieScore, 0)
+
+	p.mtx.Lock()
+	// room could be stopped while we were waiting for zombies.
+	if p.ctx.Err() != nil {
+		p.mtx.Unlock()
+		return
+	}
+	p.round, p.stopRound = context.WithCancel(p.ctx)
+	// minions live only for one round.
+	for minion := range p.minions {
+		p.Zombies, _ = removeZombie(p.Zombies, minion)
+	}
+	p.minions = map[types.Zombie]bool{}
+	for _, zombie := range p.Zombies {
+		zombie.Reset(p.width, zombies.RandomPos(0, p.height))
+		zombie.Summon(p.round, p.zombieEvents, p.bounds())
+		zombie.Run()
+	}
+	p.running = true
+	p.votes = map[types.Player]bool{}
+	for player := range p.stats {
+		p.stats[player] = &TheWallStats{}
+	}
+	p.mtx.Unlock()
+
+	log.Printf("new round started in %s", p.name)
+	p.notifyPlayers("# new round started! Zombies are coming !!!\n")
+	p.sendScore(0)
+	p.sendScore(1)
+}
+
+// Restart will start new round if game is over. This way finished room can be
+// reused without waiting for rematch votes. New round is started by room event
+// loop and Restart blocks until it is started. ErrRoomStopped will be returned
+// if room is stopped.
+func (p *TheWall) Restart() error {
+	done := make(chan struct{})
+	select {
+	case p.restart <- done:
+	case <-p.ctx.Done():
+		return ErrRoomStopped
+	}
+	select {
+	case <-done:
+		return nil
+	case <-p.ctx.Done():
+		return ErrRoomStopped
+	}
+}
+
+// Stats will return statistics of given player in current round.
+func (p *TheWall) Stats(player types.Player) TheWallStats {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if stats, ok := p.stats[player]; ok {
+		return *stats
+	}
+	return TheWallStats{}
+}
+
+// sendScore will send SCORE event of team with given index in Scores to
+// players.
+func (p *TheWall) sendScore(team int) {
+	score := p.Scores()[team]
+	p.sendEventToPlayers(types.Event{
+		Type:   types.EventScore,
+		Actor:  score.Team,
+		Points: int(score.Points),
+		Max:    int(score.Max),
+	})
+}
+
+// Commands will return commands that can be used only in this room.
+func (p *TheWall) Commands() []types.Command {
+	return []types.Command{
+		{
+			Name: types.EventScore,
+			Help: "show scoreboard of this round",
+		},
+	}
+}
+
+// scoreboard will produce scoreboard of current round. Players are sorted by
+// kills e.g.:
+//
+//	# scoreboard:
+//	#    NAME              KILLS  SHOTS  ACCURACY  BREACHES
+//	#    vanagas               2      4       50%         1
+func (p *TheWall) scoreboard() string {
+	type row struct {
+		name  string
+		stats TheWallStats
+	}
+	var rows []row
+	p.mtx.Lock()
+	for _, player := range p.players {
+		if stats, ok := p.stats[player]; ok {
+			rows = append(rows, row{player.Name(), *stats})
+		}
+	}
+	p.mtx.Unlock()
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].stats.Kills != rows[j].stats.Kills {
+			return rows[i].stats.Kills > rows[j].stats.Kills
+		}
+		return rows[i].name < rows[j].name
+	})
+
+	msg := "# scoreboard:\n"
+	msg += fmt.Sprintf("#    %-16s %6s %6s %9s %9s\n", "NAME", "KILLS", "SHOTS", "ACCURACY", "BREACHES")
+	for _, r := range rows {
+		msg += fmt.Sprintf("#    %-16s %6d %6d %8d%% %9d\n", r.name, r.stats.Kills, r.stats.Shots, r.stats.Accuracy(), r.stats.Breaches)
+	}
+	return msg
+}
+
+// notifyPlayers will send notification to all players and spectators in this
+// room.
+func (p *TheWall) notifyPlayers(msg string) {
+	for _, player := range append(p.playerList(), p.Spectators()...) {
+		player.Notify(msg)
 	}
-	return
 }
 
 // hello will produce hello message of this room, that will be sent to player
@@ -258,9 +751,49 @@ func (p *TheWall) endGame(reason string) {
 func (p *TheWall) hello() string {
 	msg := "# " + p.name + "\n"
 	msg += "# Zombies are coming !!! Prepare your bows warriors !!!\n"
+	msg += "# Rules: " + p.Rules() + "\n"
 	return msg
 }
 
+// onMap will return true if given coordinates are inside the map.
+func (p *TheWall) onMap(x, y int64) bool {
+	return p.bounds().Contains(x, y)
+}
+
+// bounds will return map of this room, so zombies know where they can go.
+func (p *TheWall) bounds() types.Bounds {
+	return types.Bounds{MaxX: p.width, MaxY: p.height}
+}
+
+func (p *TheWall) isRunning() bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.running
+}
+
+func (p *TheWall) playerList() []types.Player {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]types.Player(nil), p.players...)
+}
+
+func (p *TheWall) hasPlayer(player types.Player) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, pl := range p.players {
+		if pl == player {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *TheWall) zombieList() []types.Zombie {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]types.Zombie(nil), p.Zombies...)
+}
+
 func (p *TheWall) getPlayerScores() int64 {
 	return atomic.LoadInt64(&p.playerScore)
 }
@@ -0,0 +1,152 @@
+package rooms
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+// Limits of TheWall options. Options can be set by any client with `NEW`
+// command, so map size, zombie counts and crawler speed are limited to keep
+// the server alive.
+const (
+	TheWallMaxWidth   = 200                    // widest map.
+	TheWallMaxHeight  = 100                    // highest map.
+	TheWallMaxSpawn   = 10                     // crawlers spawned per player.
+	TheWallMaxZombies = 100                    // crawlers spawned when room starts.
+	TheWallMaxBosses  = 10                     // bosses spawned when room starts.
+	TheWallMinTick    = 100 * time.Millisecond // fastest crawler tick.
+)
+
+// TheWallOptions holds rules for TheWall room. These options can be set when
+// room is constructed or passed with `NEW <name> wall [key=value...]` command.
+type TheWallOptions struct {
+	Width, Height    int64         // map size, zombies spawn at Width-1.
+	PlayerScore      int64         // zombies players needs to kill to win.
+	ZombieScore      int64         // wall breaches zombies needs to win.
+	CrawlerTick      time.Duration // how often crawlers move.
+	ZombiesPerPlayer int           // crawlers spawned when player joins.
+	InitialZombies   int           // crawlers spawned when room starts.
+	Bosses           int           // bosses spawned when room starts.
+	RematchQuorum    float64       // part of players needed for rematch.
+	AutoRestart      time.Duration // countdown for new round, 0 disables.
+}
+
+// DefaultTheWallOptions will return default rules for TheWall room.
+func DefaultTheWallOptions() TheWallOptions {
+	return TheWallOptions{
+		Width:            TheWallMapWidth + 1,
+		Height:           TheWallMapHeight + 1,
+		PlayerScore:      TheWallMaxPlayerScore,
+		ZombieScore:      TheWallMaxZombieScore,
+		CrawlerTick:      zombies.DefaultCrawlerTick,
+		ZombiesPerPlayer: 1,
+		InitialZombies:   0,
+		RematchQuorum:    0.5,
+		AutoRestart:      0,
+	}
+}
+
+// ParseTheWallOptions will apply room options from `NEW` command on top of
+// default TheWall rules. Supported options are:
+//
+//	width=30     map width
+//	height=10    map height
+//	kills=5      zombies players needs to kill to win
+//	breaches=5   wall breaches zombies needs to win
+//	tick=3s      how often crawlers move
+//	spawn=1      crawlers spawned when player joins
+//	zombies=0    crawlers spawned when room starts
+//	bosses=0     bosses spawned when room starts
+//	quorum=0.5   part of players that must vote for a rematch
+//	autorestart=0s  countdown for new round after game over, 0s disables
+func ParseTheWallOptions(opts types.RoomOptions) (TheWallOptions, error) {
+	o := DefaultTheWallOptions()
+	for key, value := range opts {
+		var err error
+		switch key {
+		case "width":
+			o.Width, err = strconv.ParseInt(value, 10, 64)
+		case "height":
+			o.Height, err = strconv.ParseInt(value, 10, 64)
+		case "kills":
+			o.PlayerScore, err = strconv.ParseInt(value, 10, 64)
+		case "breaches":
+			o.ZombieScore, err = strconv.ParseInt(value, 10, 64)
+		case "tick":
+			o.CrawlerTick, err = time.ParseDuration(strings.ToLower(value))
+		case "spawn":
+			o.ZombiesPerPlayer, err = strconv.Atoi(value)
+		case "zombies":
+			o.InitialZombies, err = strconv.Atoi(value)
+		case "bosses":
+			o.Bosses, err = strconv.Atoi(value)
+		case "quorum":
+			o.RematchQuorum, err = strconv.ParseFloat(value, 64)
+		case "autorestart":
+			o.AutoRestart, err = time.ParseDuration(strings.ToLower(value))
+		default:
+			return o, &types.OptionError{Key: key, Msg: "unknown option"}
+		}
+		if err != nil {
+			return o, &types.OptionError{Key: key, Msg: value}
+		}
+	}
+	return o, o.Validate()
+}
+
+// Validate will return types.OptionError if rules does not make sense.
+func (o TheWallOptions) Validate() error {
+	switch {
+	case o.Width < 2:
+		return &types.OptionError{Key: "width", Msg: "must be at least 2"}
+	case o.Width > TheWallMaxWidth:
+		return &types.OptionError{Key: "width", Msg: fmt.Sprintf("must be at most %d", TheWallMaxWidth)}
+	case o.Height < 2:
+		return &types.OptionError{Key: "height", Msg: "must be at least 2"}
+	case o.Height > TheWallMaxHeight:
+		return &types.OptionError{Key: "height", Msg: fmt.Sprintf("must be at most %d", TheWallMaxHeight)}
+	case o.PlayerScore < 1:
+		return &types.OptionError{Key: "kills", Msg: "must be at least 1"}
+	case o.ZombieScore < 1:
+		return &types.OptionError{Key: "breaches", Msg: "must be at least 1"}
+	case o.CrawlerTick < TheWallMinTick:
+		return &types.OptionError{Key: "tick", Msg: fmt.Sprintf("must be at least %s", TheWallMinTick)}
+	case o.ZombiesPerPlayer < 0:
+		return &types.OptionError{Key: "spawn", Msg: "cannot be negative"}
+	case o.ZombiesPerPlayer > TheWallMaxSpawn:
+		return &types.OptionError{Key: "spawn", Msg: fmt.Sprintf("must be at most %d", TheWallMaxSpawn)}
+	case o.InitialZombies < 0:
+		return &types.OptionError{Key: "zombies", Msg: "cannot be negative"}
+	case o.InitialZombies > TheWallMaxZombies:
+		return &types.OptionError{Key: "zombies", Msg: fmt.Sprintf("must be at most %d", TheWallMaxZombies)}
+	case o.Bosses < 0:
+		return &types.OptionError{Key: "bosses", Msg: "cannot be negative"}
+	case o.Bosses > TheWallMaxBosses:
+		return &types.OptionError{Key: "bosses", Msg: fmt.Sprintf("must be at most %d", TheWallMaxBosses)}
+	case o.RematchQuorum < 0 || o.RematchQuorum > 1:
+		return &types.OptionError{Key: "quorum", Msg: "must be between 0 and 1"}
+	case o.AutoRestart < 0:
+		return &types.OptionError{Key: "autorestart", Msg: "cannot be negative"}
+	}
+	return nil
+}
+
+// String will describe rules in human readable form.
+func (o TheWallOptions) String() string {
+	s := fmt.Sprintf(
+		"map %dx%d, players win after %d kills, zombies win after %d wall breaches, crawlers move every %s",
+		o.Width, o.Height, o.PlayerScore, o.ZombieScore, o.CrawlerTick,
+	)
+	if o.Bosses > 0 {
+		s += fmt.Sprintf(", %d bosses lead zombies", o.Bosses)
+	}
+	if o.AutoRestart > 0 {
+		s += fmt.Sprintf(", new round starts %s after game over", o.AutoRestart)
+	}
+	return s
+}
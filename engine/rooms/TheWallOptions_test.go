@@ -0,0 +1,112 @@
+package rooms_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/rooms"
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+func TestParseTheWallOptions(t *testing.T) {
+	testTable := []struct {
+		Options         types.RoomOptions
+		ExpectedOptions rooms.TheWallOptions
+		ExpectErr       bool
+	}{
+		{
+			Options:         types.RoomOptions{},
+			ExpectedOptions: rooms.DefaultTheWallOptions(),
+		},
+		{
+			Options: types.RoomOptions{
+				"width":       "20",
+				"height":      "5",
+				"kills":       "3",
+				"breaches":    "2",
+				"tick":        "500MS",
+				"spawn":       "0",
+				"zombies":     "4",
+				"bosses":      "1",
+				"quorum":      "1",
+				"autorestart": "10S",
+			},
+			ExpectedOptions: rooms.TheWallOptions{
+				Width:            20,
+				Height:           5,
+				PlayerScore:      3,
+				ZombieScore:      2,
+				CrawlerTick:      500 * time.Millisecond,
+				ZombiesPerPlayer: 0,
+				InitialZombies:   4,
+				Bosses:           1,
+				RematchQuorum:    1,
+				AutoRestart:      10 * time.Second,
+			},
+		},
+		{
+			Options:   types.RoomOptions{"width": "wide"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"width": "1"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"kills": "0"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"bosses": "-1"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"quorum": "1.5"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"zombies": "10000000"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"spawn": "11"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"bosses": "11"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"width": "1000"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"height": "1000"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"tick": "1ns"},
+			ExpectErr: true,
+		},
+		{
+			Options:   types.RoomOptions{"color": "red"},
+			ExpectErr: true,
+		},
+	}
+
+	for idx, c := range testTable {
+		opts, err := rooms.ParseTheWallOptions(c.Options)
+		if c.ExpectErr {
+			if err == nil {
+				t.Errorf("expected error: case %d", idx)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: case %d, got: %s", idx, err)
+		}
+		if opts != c.ExpectedOptions {
+			t.Errorf("wrong options: case %d, got: %+v, want: %+v", idx, opts, c.ExpectedOptions)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package rooms_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -73,6 +74,17 @@ func TestTheWallPlayersWin(t *testing.T) {
 	if room.ZombiesWon() {
 		t.Errorf("zombies should lose")
 	}
+
+	// players should receive machine-readable game result.
+	for i := 0; i < 1000; i++ {
+		for _, e := range player.Processed() {
+			if e.Type == types.EventGameOver && e.Actor == "players" {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("expected GAMEOVER event, got: %v", player.Processed())
 }
 
 func TestTheWallZombiesWin(t *testing.T) {
@@ -97,3 +109,491 @@ func TestTheWallZombiesWin(t *testing.T) {
 		t.Errorf("players should lose")
 	}
 }
+
+func TestTheWallOptions(t *testing.T) {
+
+	player := &players.MockPlayer{
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:            10,
+			Height:           3,
+			PlayerScore:      1,
+			ZombieScore:      1,
+			CrawlerTick:      time.Hour,
+			ZombiesPerPlayer: 2,
+			InitialZombies:   1,
+		},
+	}
+	room.Init()
+
+	if len(room.Zombies) != 1 {
+		t.Errorf("wrong initial zombie count: got: %d, want: 1", len(room.Zombies))
+	}
+
+	room.AddPlayer(player)
+
+	if len(room.Zombies) != 3 {
+		t.Errorf("wrong zombie count after player joined: got: %d, want: 3", len(room.Zombies))
+	}
+
+	zombie := room.Zombies[0]
+	if x, _ := zombie.GetPos(); x != 9 {
+		t.Errorf("wrong zombie x position: got: %d, want: 9", x)
+	}
+
+	x, y := zombie.GetPos()
+	player.ProduceEvent(types.Event{
+		Type: types.EventShoot,
+		X:    x,
+		Y:    y,
+	})
+	room.Process()
+
+	if !room.PlayersWon() {
+		t.Errorf("expected victory for players after one kill")
+	}
+}
+
+func TestTheWallState(t *testing.T) {
+
+	player := &players.MockPlayer{
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:            10,
+			Height:           3,
+			PlayerScore:      1,
+			ZombieScore:      1,
+			CrawlerTick:      time.Hour,
+			ZombiesPerPlayer: 1,
+		},
+	}
+	room.Init()
+
+	if state := room.State(); state != types.RoomWaiting {
+		t.Errorf("wrong room state: got: %s, want: %s", state, types.RoomWaiting)
+	}
+
+	room.AddPlayer(player)
+
+	if state := room.State(); state != types.RoomRunning {
+		t.Errorf("wrong room state: got: %s, want: %s", state, types.RoomRunning)
+	}
+
+	if count := len(room.Players()); count != 1 {
+		t.Errorf("wrong player count: got: %d, want: 1", count)
+	}
+
+	x, y := room.Zombies[0].GetPos()
+	player.ProduceEvent(types.Event{
+		Type: types.EventShoot,
+		X:    x,
+		Y:    y,
+	})
+	room.Process()
+
+	if state := room.State(); state != types.RoomFinished {
+		t.Errorf("wrong room state: got: %s, want: %s", state, types.RoomFinished)
+	}
+
+	// player leaves, so room should forget him. Events of removed player
+	// are ignored, but his stream is still read.
+	room.RemovePlayer(player)
+	if count := len(room.Players()); count != 0 {
+		t.Errorf("wrong player count after leave: got: %d, want: 0", count)
+	}
+	done := make(chan struct{})
+	go func() {
+		player.ProduceEvent(types.Event{Type: types.EventShoot})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("stream of removed player is not read")
+	}
+	close(player.Events)
+}
+
+func TestTheWallRematch(t *testing.T) {
+
+	player1 := &players.MockPlayer{
+		Events: make(chan types.Event),
+	}
+	player2 := &players.MockPlayer{
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:            10,
+			Height:           3,
+			PlayerScore:      1,
+			ZombieScore:      1,
+			CrawlerTick:      time.Hour,
+			ZombiesPerPlayer: 1,
+			RematchQuorum:    1,
+		},
+	}
+	room.Init()
+	room.AddPlayer(player1)
+	room.AddPlayer(player2)
+
+	x, y := room.Zombies[0].GetPos()
+	player1.ProduceEvent(types.Event{
+		Type: types.EventShoot,
+		X:    x,
+		Y:    y,
+	})
+	room.Process()
+
+	if !room.PlayersWon() || room.State() != types.RoomFinished {
+		t.Fatalf("expected victory for players")
+	}
+
+	// both players must agree, so one vote is not enough.
+	player1.ProduceEvent(types.Event{Type: types.EventRematch})
+	room.Process()
+
+	if room.State() != types.RoomFinished {
+		t.Errorf("new round should not start before quorum is reached")
+	}
+
+	player2.ProduceEvent(types.Event{Type: types.EventRematch})
+	room.Process()
+
+	if state := room.State(); state != types.RoomRunning {
+		t.Errorf("wrong room state after rematch: got: %s, want: %s", state, types.RoomRunning)
+	}
+
+	if room.PlayersWon() || room.ZombiesWon() {
+		t.Errorf("scores should be reset after rematch")
+	}
+
+	if x, _ := room.Zombies[0].GetPos(); x != 9 {
+		t.Errorf("zombie should be respawned: got x: %d, want: 9", x)
+	}
+
+	room.Stop()
+}
+
+func TestTheWallAutoRestart(t *testing.T) {
+
+	player := &players.MockPlayer{
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:            10,
+			Height:           3,
+			PlayerScore:      1,
+			ZombieScore:      1,
+			CrawlerTick:      time.Hour,
+			ZombiesPerPlayer: 1,
+			AutoRestart:      50 * time.Millisecond,
+		},
+	}
+	room.Init()
+	room.AddPlayer(player)
+	room.Run()
+	defer room.Stop()
+
+	x, y := room.Zombies[0].GetPos()
+	player.ProduceEvent(types.Event{
+		Type: types.EventShoot,
+		X:    x,
+		Y:    y,
+	})
+
+	// wait for game over and automatic restart.
+	finished := false
+	for i := 0; i < 1000; i++ {
+		state := room.State()
+		if state == types.RoomFinished {
+			finished = true
+		}
+		if finished && state == types.RoomRunning {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("room was not restarted automatically")
+}
+
+func TestTheWallStats(t *testing.T) {
+
+	alice := &players.MockPlayer{
+		Nick:   "alice",
+		Events: make(chan types.Event),
+	}
+	bob := &players.MockPlayer{
+		Nick:   "bob",
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:          10,
+			Height:         3,
+			PlayerScore:    10,
+			ZombieScore:    10,
+			CrawlerTick:    time.Hour,
+			InitialZombies: 1,
+		},
+	}
+	room.Init()
+	defer room.Stop()
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+	zombie := room.Zombies[0]
+
+	shoot := func(player *players.MockPlayer, x, y int64) {
+		player.ProduceEvent(types.Event{
+			Type:  types.EventShoot,
+			Actor: player.Name(),
+			X:     x,
+			Y:     y,
+		})
+		room.Process()
+	}
+
+	shootZombie := func(player *players.MockPlayer) {
+		x, y := zombie.GetPos()
+		shoot(player, x, y)
+	}
+
+	shootZombie(alice)
+	shoot(alice, 0, 0)
+	shootZombie(bob)
+	shootZombie(alice)
+
+	expected := rooms.TheWallStats{Kills: 2, Shots: 3, Hits: 2}
+	if stats := room.Stats(alice); stats != expected {
+		t.Errorf("wrong alice stats: got: %+v, want: %+v", stats, expected)
+	}
+	if accuracy := room.Stats(alice).Accuracy(); accuracy != 66 {
+		t.Errorf("wrong alice accuracy: got: %d, want: 66", accuracy)
+	}
+	expected = rooms.TheWallStats{Kills: 1, Shots: 1, Hits: 1}
+	if stats := room.Stats(bob); stats != expected {
+		t.Errorf("wrong bob stats: got: %+v, want: %+v", stats, expected)
+	}
+
+	// BOOM points should be running total of the shooter.
+	for i := 0; i < 1000; i++ {
+		for _, e := range bob.Processed() {
+			if e.Type == types.EventBoom && e.Actor == "alice" && e.Points == 2 {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("expected BOOM event with alice running total, got: %v", bob.Processed())
+}
+
+func TestTheWallArmored(t *testing.T) {
+	alice := &players.MockPlayer{
+		Nick:   "alice",
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:       10,
+			Height:      3,
+			PlayerScore: 10,
+			ZombieScore: 10,
+			CrawlerTick: time.Hour,
+		},
+	}
+	room.Init()
+	defer room.Stop()
+	room.AddPlayer(alice)
+
+	zombie := &zombies.Armored{MaxHP: 2, Tick: time.Hour}
+	room.AddZombie(zombie)
+	zombie.Reset(2, 1)
+
+	lastBoom := func() types.Event {
+		processed := alice.Processed()
+		for i := len(processed) - 1; i >= 0; i-- {
+			if processed[i].Type == types.EventBoom {
+				return processed[i]
+			}
+		}
+		return types.Event{}
+	}
+
+	// first arrow lands, but armor holds.
+	alice.ProduceEvent(types.Event{Type: types.EventShoot, Actor: "alice", X: 2, Y: 1})
+	room.Process()
+
+	expected := fmt.Sprintf("BOOM alice 0 [%s:1/2]", zombie.GetName())
+	if boom := lastBoom(); boom.String() != expected {
+		t.Errorf("wrong BOOM event: got: '%s', want: '%s'", boom.String(), expected)
+	}
+	if x, y := zombie.GetPos(); x != 2 || y != 1 {
+		t.Errorf("armored zombie should not respawn after first hit: got: %d %d", x, y)
+	}
+
+	// second arrow kills it.
+	alice.ProduceEvent(types.Event{Type: types.EventShoot, Actor: "alice", X: 2, Y: 1})
+	room.Process()
+
+	expected = fmt.Sprintf("BOOM alice 1 [%s:0/2]", zombie.GetName())
+	if boom := lastBoom(); boom.String() != expected {
+		t.Errorf("wrong BOOM event: got: '%s', want: '%s'", boom.String(), expected)
+	}
+	if x, _ := zombie.GetPos(); x != 9 {
+		t.Errorf("killed zombie should respawn: got x: %d, want: 9", x)
+	}
+	if hp, _ := zombie.HP(); hp != 2 {
+		t.Errorf("respawned zombie should have new armor: got hp: %d, want: 2", hp)
+	}
+}
+
+func TestTheWallBoss(t *testing.T) {
+	alice := &players.MockPlayer{
+		Nick:   "alice",
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:       10,
+			Height:      3,
+			PlayerScore: 10,
+			ZombieScore: 10,
+			CrawlerTick: time.Hour,
+		},
+	}
+	room.Init()
+	defer room.Stop()
+	room.AddPlayer(alice)
+
+	boss := &zombies.Boss{Tick: time.Hour, SpawnEvery: time.Hour, Minions: 2}
+	room.AddZombie(boss)
+
+	// boss announces himself when he appears.
+	room.Process()
+	expected := fmt.Sprintf("BOSS %s march %d/%d", boss.GetName(), zombies.DefaultBossHP, zombies.DefaultBossHP)
+	processed := alice.Processed()
+	if last := processed[len(processed)-1]; last.String() != expected {
+		t.Errorf("wrong announcement: got: '%s', want: '%s'", last.String(), expected)
+	}
+
+	// room spawns minions next to the boss, but SPAWN is not sent to
+	// players.
+	go boss.Spawn()
+	room.Process()
+	room.Process()
+
+	if count := room.ZombieCount(); count != 3 {
+		t.Fatalf("wrong zombie count: got: %d, want: 3", count)
+	}
+	bx, by := boss.GetPos()
+	for _, zombie := range room.Zombies[1:] {
+		if x, y := zombie.GetPos(); x != bx || y != by {
+			t.Errorf("minion should appear next to boss: got: %d %d, want: %d %d", x, y, bx, by)
+		}
+	}
+	for _, e := range alice.Processed() {
+		if e.Type == types.EventSpawn {
+			t.Errorf("SPAWN should not be sent to players: %s", e.String())
+		}
+	}
+}
+
+func TestTheWallSnapshot(t *testing.T) {
+	alice := &players.MockPlayer{
+		Nick:   "alice",
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:          10,
+			Height:         3,
+			PlayerScore:    10,
+			ZombieScore:    10,
+			CrawlerTick:    time.Hour,
+			InitialZombies: 1,
+		},
+	}
+	room.Init()
+	defer room.Stop()
+	room.AddPlayer(alice)
+
+	zombie := room.Zombies[0]
+	x, y := zombie.GetPos()
+	alice.ProduceEvent(types.Event{Type: types.EventShoot, Actor: "alice", X: x, Y: y})
+	room.Process()
+
+	x, y = zombie.GetPos()
+	expected := []string{
+		fmt.Sprintf("WALK %s %d %d", zombie.GetName(), x, y),
+		"SCORE players 1 10",
+		"SCORE zombies 0 10",
+	}
+	snapshot := room.Snapshot()
+	if len(snapshot) != len(expected) {
+		t.Fatalf("wrong snapshot: got: %v, want: %v", snapshot, expected)
+	}
+	for i, e := range snapshot {
+		if e.String() != expected[i] {
+			t.Errorf("wrong snapshot event %d: got: '%s', want: '%s'", i, e.String(), expected[i])
+		}
+	}
+}
+
+func TestTheWallSpectators(t *testing.T) {
+	alice := &players.MockPlayer{
+		Nick:   "alice",
+		Events: make(chan types.Event),
+	}
+	guest := &players.MockPlayer{
+		Nick:   "guest",
+		Events: make(chan types.Event),
+	}
+
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:            10,
+			Height:           3,
+			PlayerScore:      10,
+			ZombieScore:      10,
+			CrawlerTick:      time.Hour,
+			ZombiesPerPlayer: 1,
+		},
+	}
+	room.Init()
+	defer room.Stop()
+	room.AddSpectator(guest)
+	room.AddPlayer(alice)
+
+	if count := room.ZombieCount(); count != 1 {
+		t.Errorf("spectator should not spawn crawlers: got %d zombies, want: 1", count)
+	}
+	if players := room.Players(); len(players) != 1 || players[0] != alice {
+		t.Errorf("spectator should not be listed as player: %v", players)
+	}
+
+	alice.ProduceEvent(types.Event{Type: types.EventShoot, Actor: "alice", X: 0, Y: 0})
+	room.Process()
+
+	for i := 0; i < 1000; i++ {
+		for _, e := range guest.Processed() {
+			if e.Type == types.EventBoom && e.Actor == "alice" {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("spectator should receive BOOM event, got: %v", guest.Processed())
+}
@@ -2,13 +2,22 @@ package rooms
 
 import (
 	"context"
+	"sync"
 
 	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+// Map size of training grounds. Zombies that jump around stay inside it.
+const (
+	TrainingGroundsMapWidth  = 29                 // map size 10x30 but we count from 0 here.
+	TrainingGroundsMapHeight = 9                  // map size
+	TrainingGroundsName      = "TRAINING-GROUNDS" // room name if name is not set
 )
 
 // TrainingGrounds satisfies engine.Room interface and can be used as playable
 // room. This room implementation can handle multiple players and zombies at
-// same time.
+// same time. Room can be watched by spectators.
 type TrainingGrounds struct {
 	Zombies      []types.Zombie
 	players      []types.Player
@@ -17,6 +26,25 @@ type TrainingGrounds struct {
 	ctx          context.Context
 	stopFunc     context.CancelFunc
 	name         string
+	stopped      bool
+	spectators
+	mtx sync.Mutex
+	wg  sync.WaitGroup
+}
+
+// NewTrainingGrounds will create TrainingGrounds room with dummy zombie inside.
+// This satisfies types.RoomFactory so it can be registered as room type in
+// server.
+func NewTrainingGrounds(opts types.RoomOptions) (types.Room, error) {
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
+	return &TrainingGrounds{
+		Zombies: []types.Zombie{
+			&zombies.Dummy{},
+		},
+		name: TrainingGroundsName,
+	}, nil
 }
 
 // Name will return rooms name.
@@ -32,57 +60,112 @@ func (p *TrainingGrounds) SetName(n string) {
 // AddPlayer will attach client to this room.
 func (p *TrainingGrounds) AddPlayer(player types.Player) error {
 	player.Notify(p.hello())
+	p.mtx.Lock()
+	p.players = append(p.players, player)
+	p.mtx.Unlock()
+	p.sendEventToPlayers(types.Event{Type: types.EventJoined, Actor: player.Name()})
+	events := player.EventStream()
 	go func() {
-		p.players = append(p.players, player)
-		for {
-			if event, open := player.GetEvent(); open {
-				p.playerEvents <- event
-			} else {
-				return
+		// stream is read until it is closed, so client never blocks
+		// on it. Server removes player from the room when he leaves
+		// or disconnects.
+		for event := range events {
+			// player could leave the room while we were waiting
+			// for event.
+			if !p.hasPlayer(player) {
+				continue
+			}
+			select {
+			case p.playerEvents <- event:
+			case <-p.ctx.Done():
 			}
 		}
 	}()
 	return nil
 }
 
-// AddZombie will attach zombie to this room.
+// RemovePlayer will detach player from this room.
+func (p *TrainingGrounds) RemovePlayer(player types.Player) error {
+	p.mtx.Lock()
+	for i, pl := range p.players {
+		if pl == player {
+			p.players = append(p.players[:i], p.players[i+1:]...)
+			p.mtx.Unlock()
+			p.sendEventToPlayers(types.Event{Type: types.EventLeft, Actor: player.Name()})
+			return nil
+		}
+	}
+	p.mtx.Unlock()
+	return ErrPlayerNotFound
+}
+
+// AddZombie will attach zombie to this room. Zombie appears at the right
+// edge of the map.
 func (p *TrainingGrounds) AddZombie(z types.Zombie) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	z.Reset(TrainingGroundsMapWidth, zombies.RandomPos(0, TrainingGroundsMapHeight+1))
 	p.Zombies = append(p.Zombies, z)
-	z.Summon(p.ctx, p.zombieEvents)
+	z.Summon(p.ctx, p.zombieEvents, p.bounds())
 	z.Run()
 	return nil
 }
 
-// Stop stops this room and kills all zombies.
+// RemoveZombie will kill zombie and remove it from this room. This is used
+// when player that plays as zombie leaves the room.
+func (p *TrainingGrounds) RemoveZombie(z types.Zombie) error {
+	p.mtx.Lock()
+	var err error
+	p.Zombies, err = removeZombie(p.Zombies, z)
+	p.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+	return z.Kill()
+}
+
+// Stop stops this room and kills all zombies. Stop will block until room and
+// all zombies inside it are stopped.
 func (p *TrainingGrounds) Stop() error {
-	defer func() {
-		close(p.zombieEvents)
-		close(p.playerEvents)
-	}()
+	if p.stopFunc == nil {
+		return nil
+	}
 	p.stopFunc()
+	p.mtx.Lock()
+	p.stopped = true
+	zombies := p.Zombies
+	p.mtx.Unlock()
+	for _, zombie := range zombies {
+		zombie.Kill()
+	}
+	p.wg.Wait()
 	return nil
 }
 
 // Init will do some room preparations.
 func (p *TrainingGrounds) Init() error {
 	if p.name == "" {
-		p.name = "TRAINING-GROUNDS"
+		p.name = TrainingGroundsName
 	}
 	p.zombieEvents = make(chan types.Event)
 	p.playerEvents = make(chan types.Event)
 	p.ctx, p.stopFunc = context.WithCancel(context.Background())
 
 	// summon all pre-defined zombies.
+	p.mtx.Lock()
 	for _, zombie := range p.Zombies {
-		zombie.Summon(p.ctx, p.zombieEvents)
+		zombie.Summon(p.ctx, p.zombieEvents, p.bounds())
 		zombie.Run()
 	}
+	p.mtx.Unlock()
 	return nil
 }
 
 // Run will initialize this room.
 func (p *TrainingGrounds) Run() error {
+	p.wg.Add(1)
 	go func() {
+		defer p.wg.Done()
 		for {
 			if err := p.Process(); err != nil {
 				return
@@ -92,8 +175,12 @@ func (p *TrainingGrounds) Run() error {
 	return nil
 }
 
+// Process will handle one queued room event. ErrRoomStopped is returned when
+// room is stopped.
 func (p *TrainingGrounds) Process() error {
 	select {
+	case <-p.ctx.Done():
+		return ErrRoomStopped
 	case playerEvent := <-p.playerEvents:
 		switch playerEvent.Type {
 		case types.EventShoot:
@@ -102,11 +189,32 @@ func (p *TrainingGrounds) Process() error {
 		default:
 		}
 	case zombieEvent := <-p.zombieEvents:
+		// training grounds does not spawn minions.
+		if zombieEvent.Type == types.EventSpawn {
+			break
+		}
 		p.sendEventToPlayers(zombieEvent)
+		// there is no wall to breach here, so zombie that crawled to
+		// the left edge is respawned at the right edge of the map.
+		if zombieEvent.Type == types.EventWalk && zombieEvent.X <= 0 {
+			p.respawn(zombieEvent.Actor)
+		}
 	}
 	return nil
 }
 
+// respawn will move zombie with given name to the right edge of the map.
+func (p *TrainingGrounds) respawn(name string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, zombie := range p.Zombies {
+		if zombie.GetName() == name {
+			zombie.Reset(TrainingGroundsMapWidth, zombies.RandomPos(0, TrainingGroundsMapHeight+1))
+			return
+		}
+	}
+}
+
 // ZombiesWon will always return false here, because we cannot win in training.
 func (p *TrainingGrounds) ZombiesWon() bool {
 	return false
@@ -117,19 +225,73 @@ func (p *TrainingGrounds) PlayersWon() bool {
 	return false
 }
 
+// State will return current state of this room. Training never ends, so room
+// is finished only when it is stopped.
+func (p *TrainingGrounds) State() types.RoomState {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	switch {
+	case p.stopped:
+		return types.RoomFinished
+	case len(p.players) > 0:
+		return types.RoomRunning
+	}
+	return types.RoomWaiting
+}
+
+// Players will return players that are currently in this room.
+func (p *TrainingGrounds) Players() []types.Player {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]types.Player(nil), p.players...)
+}
+
+// ZombieCount will return how many zombies are living in this room.
+func (p *TrainingGrounds) ZombieCount() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return len(p.Zombies)
+}
+
+// Rules will describe rules of this room.
+func (p *TrainingGrounds) Rules() string {
+	return "training never ends and no scores are kept"
+}
+
+// bounds will return map of this room, so zombies know where they can go.
+func (p *TrainingGrounds) bounds() types.Bounds {
+	return types.Bounds{MaxX: TrainingGroundsMapWidth, MaxY: TrainingGroundsMapHeight}
+}
+
+func (p *TrainingGrounds) hasPlayer(player types.Player) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, pl := range p.players {
+		if pl == player {
+			return true
+		}
+	}
+	return false
+}
+
+// sendEventToPlayers will send event to all players and spectators in this
+// room. Players queue events, so events arrive in the same order as they were
+// sent.
 func (p *TrainingGrounds) sendEventToPlayers(e types.Event) {
-	for _, player := range p.players {
-		go player.ProcessEvent(e)
+	for _, player := range append(p.Players(), p.Spectators()...) {
+		player.ProcessEvent(e)
 	}
 }
 
 func (p *TrainingGrounds) processShootEvent(e types.Event) types.Event {
 	hits := []string{}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
 	for _, zombie := range p.Zombies {
 		x, y := zombie.GetPos()
 		if x == e.X && y == e.Y {
-			hits = append(hits, zombie.GetName())
 			zombie.Hit()
+			hits = append(hits, hitName(zombie))
 		}
 	}
 	shootResult := types.Event{
@@ -2,6 +2,7 @@ package rooms_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/sheirys/zombebattle/engine/players"
 	"github.com/sheirys/zombebattle/engine/rooms"
@@ -42,3 +43,45 @@ func TestTrainingGrounds(t *testing.T) {
 		t.Errorf("should be impossible to win TrainingGrounds for zombies")
 	}
 }
+
+func TestTrainingGroundsRemoveZombie(t *testing.T) {
+	zombie := &zombies.Dummy{}
+
+	room := &rooms.TrainingGrounds{}
+	room.Init()
+	defer room.Stop()
+	room.AddZombie(zombie)
+
+	if err := room.RemoveZombie(zombie); err != nil {
+		t.Errorf("unexpected remove error: %s", err)
+	}
+	if count := room.ZombieCount(); count != 0 {
+		t.Errorf("zombie should be removed: got %d zombies, want: 0", count)
+	}
+	if err := room.RemoveZombie(zombie); err != rooms.ErrZombieNotFound {
+		t.Errorf("wrong error: got: %v, want: %v", err, rooms.ErrZombieNotFound)
+	}
+}
+
+func TestTrainingGroundsRespawn(t *testing.T) {
+	zombie := &zombies.Crawler{Tick: time.Hour}
+
+	room := &rooms.TrainingGrounds{}
+	room.Init()
+	defer room.Stop()
+	room.AddZombie(zombie)
+
+	if x, _ := zombie.GetPos(); x != rooms.TrainingGroundsMapWidth {
+		t.Errorf("zombie should appear at the edge: got x: %d, want: %d", x, rooms.TrainingGroundsMapWidth)
+	}
+
+	// crawler reaches the left edge and should be respawned instead of
+	// crawling out of the map.
+	zombie.Reset(1, 5)
+	go zombie.Next()
+	room.Process()
+
+	if x, _ := zombie.GetPos(); x != rooms.TrainingGroundsMapWidth {
+		t.Errorf("zombie should respawn at the edge: got x: %d, want: %d", x, rooms.TrainingGroundsMapWidth)
+	}
+}
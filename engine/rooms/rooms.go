@@ -0,0 +1,124 @@
+package rooms
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+var (
+	// ErrRoomStopped will be returned by Process when room is stopped and
+	// cannot handle events anymore.
+	ErrRoomStopped = errors.New("room is stopped")
+
+	// ErrPlayerNotFound will be returned by RemovePlayer when player is not
+	// in the room.
+	ErrPlayerNotFound = errors.New("player is not in the room")
+
+	// ErrZombieNotFound will be returned by RemoveZombie when zombie is not
+	// in the room.
+	ErrZombieNotFound = errors.New("zombie is not in the room")
+)
+
+// checkOptions will return error if opts contains option that is not in known
+// options list.
+func checkOptions(opts types.RoomOptions, known ...string) error {
+	for key := range opts {
+		found := false
+		for _, k := range known {
+			if k == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &types.OptionError{Key: key, Msg: "unknown option"}
+		}
+	}
+	return nil
+}
+
+// playerEvent is event produced by player. Rooms use it to know which player
+// produced the event.
+type playerEvent struct {
+	player types.Player
+	event  types.Event
+}
+
+// removeZombie will return new list without given zombie, so copies of the old
+// list taken by Stop stay intact. ErrZombieNotFound is returned if zombie is
+// not in the list.
+func removeZombie(list []types.Zombie, z types.Zombie) ([]types.Zombie, error) {
+	rest := make([]types.Zombie, 0, len(list))
+	for _, zombie := range list {
+		if zombie != z {
+			rest = append(rest, zombie)
+		}
+	}
+	if len(rest) == len(list) {
+		return list, ErrZombieNotFound
+	}
+	return rest, nil
+}
+
+// hitName will describe zombie hit by arrow in BOOM event. Zombies that report
+// health are described with health left after hit e.g. `armored-bob:2/3`.
+func hitName(z types.Zombie) string {
+	if h, ok := z.(types.HealthReporter); ok {
+		hp, maxHP := h.HP()
+		return types.FormatHit(z.GetName(), hp, maxHP)
+	}
+	return z.GetName()
+}
+
+// walkEvent will describe current position and health of zombie.
+func walkEvent(z types.Zombie) types.Event {
+	x, y := z.GetPos()
+	e := types.Event{
+		Type:  types.EventWalk,
+		Actor: z.GetName(),
+		X:     x,
+		Y:     y,
+	}
+	if h, ok := z.(types.HealthReporter); ok {
+		e.HP, e.MaxHP = h.HP()
+	}
+	return e
+}
+
+// spectators holds players that watch the room. Rooms embed it to satisfy
+// types.Watchable. Spectators receive events sent to players, but room does
+// not read events from them.
+type spectators struct {
+	list []types.Player
+	mtx  sync.Mutex
+}
+
+// AddSpectator will attach spectator to this room.
+func (s *spectators) AddSpectator(player types.Player) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.list = append(s.list, player)
+	return nil
+}
+
+// RemoveSpectator will detach spectator from this room.
+func (s *spectators) RemoveSpectator(player types.Player) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for i, pl := range s.list {
+		if pl == player {
+			s.list = append(s.list[:i], s.list[i+1:]...)
+			return nil
+		}
+	}
+	return ErrPlayerNotFound
+}
+
+// Spectators will return spectators that are currently watching this room.
+func (s *spectators) Spectators() []types.Player {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]types.Player(nil), s.list...)
+}
@@ -1,51 +1,296 @@
 package engine
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
 	"net"
-	"os"
-	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
-	"syscall"
+	"time"
 
 	"github.com/sheirys/zombebattle/engine/rooms"
 	"github.com/sheirys/zombebattle/engine/types"
 )
 
-var killSignals = []os.Signal{syscall.SIGTERM, os.Interrupt, os.Kill}
+const (
+	// DefaultShutdownTimeout is used by Run when context is canceled and
+	// ShutdownTimeout is not set.
+	DefaultShutdownTimeout = 5 * time.Second
+
+	// DefaultRoomType will be used when client creates new room with
+	// `NEW <name>` command without room type.
+	DefaultRoomType = "wall"
+
+	// DefaultReapInterval is used when ReapInterval is not set.
+	DefaultReapInterval = time.Second
+)
+
+var (
+	// ErrServerClosed will be returned when server is already shut down.
+	ErrServerClosed = errors.New("server closed")
+)
+
+// CommandHandler handles command registered in server. Player is client that
+// typed the command.
+type CommandHandler func(player types.Player, e types.Event) error
 
 // Server holds information about game server.
 type Server struct {
 	Addr        string
 	DefaultRoom types.Room
 	Rooms       []types.ServerRoom
-	newClient   chan net.Conn
-	stop        chan os.Signal
-	command     chan types.Event
-	roomsMtx    sync.Mutex
+
+	// ShutdownTimeout defines how long Run will wait for rooms to stop
+	// when context passed to Run is canceled.
+	ShutdownTimeout time.Duration
+
+	// MaxClients limits how many clients can be connected at the same
+	// time. MaxRooms limits how many rooms can exist in server. Zero means
+	// no limit.
+	MaxClients int
+	MaxRooms   int
+
+	// RoomIdleTimeout defines how long room can stay empty before it is
+	// removed from server. Default room is never removed. Zero means that
+	// empty rooms are kept until game in them is finished.
+	RoomIdleTimeout time.Duration
+
+	// ReapInterval defines how often server checks for finished and idle
+	// rooms. Zero means DefaultReapInterval.
+	ReapInterval time.Duration
+
+	// ClientQueueSize limits how many messages can wait to be written to
+	// one client and ClientWriteTimeout limits how long one write can
+	// take. SlowClientPolicy decides what happens when client queue is
+	// full. Zero values mean DefaultClientQueueSize,
+	// DefaultClientWriteTimeout and DropWalks.
+	ClientQueueSize    int
+	ClientWriteTimeout time.Duration
+	SlowClientPolicy   SlowClientPolicy
+
+	// MaxLineLength limits how long line client can send. Longer lines
+	// are discarded and client receives `ERR LINE_TOO_LONG`. Zero means
+	// DefaultMaxLineLength.
+	MaxLineLength int
+
+	// LobbyIdleTimeout and GameIdleTimeout define how long client can stay
+	// silent in the lobby or in the room. When timeout fires, client is
+	// removed from the room and disconnected. Zero means no timeout.
+	LobbyIdleTimeout time.Duration
+	GameIdleTimeout  time.Duration
+
+	// ResumeTimeout defines how long disconnected player keeps his place
+	// in the room. Player receives `TOKEN <token>` when game starts and
+	// can return with `RESUME <token>` from new connection. Zero means
+	// that players are removed from the room as soon as they disconnect.
+	ResumeTimeout time.Duration
+
+	// ZombieCooldown defines how often players that play as zombies with
+	// `JOIN <room> AS ZOMBIE` can move. Zero means
+	// zombies.DefaultRemoteCooldown.
+	ZombieCooldown time.Duration
+
+	// ChatLimit defines how many chat messages player can send during
+	// ChatInterval. Zero values mean DefaultChatLimit and
+	// DefaultChatInterval. ChatFilter can change or reject chat messages,
+	// see WordFilter.
+	ChatLimit    int
+	ChatInterval time.Duration
+	ChatFilter   ChatFilter
+
+	listener  net.Listener
+	newClient chan net.Conn
+	done      chan struct{}
+	roomsMtx  sync.Mutex
+
+	clients    map[*Client]types.Room // nil room means lobby.
+	names      map[string]*Client
+	parked     map[string]chan *Client
+	zombies    map[types.Player]bool // clients that play as zombies.
+	lastID     uint64
+	dropped    int
+	clientsMtx sync.Mutex
+
+	roomTypes    map[string]types.RoomFactory
+	roomTypesMtx sync.Mutex
+
+	commands    *CommandSet
+	handlers    map[string]CommandHandler
+	handlersMtx sync.Mutex
+
+	initOnce  sync.Once
+	closeOnce sync.Once
+	listenMtx sync.Mutex
 }
 
-// Run starts to listen for events and handle them.
-func (s *Server) Run() {
-	s.init()
+// Run starts to listen for events and handle them. Run will block until ctx
+// is canceled or Shutdown is called. When ctx is canceled, server will be shut
+// down gracefully and error will be returned only if shutdown fails.
+func (s *Server) Run(ctx context.Context) error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
+	s.addDefaultRoom()
 	s.startRooms()
+
+	reaper := time.NewTicker(s.reapInterval())
+	defer reaper.Stop()
+	emptySince := map[types.Room]time.Time{}
+
 	for {
 		select {
 		case connection := <-s.newClient:
 			go s.acceptClient(connection)
-		case command := <-s.command:
-			// FIXME: For now only one EventNew is supported.
-			// FIXME: New maps will always be rooms.TheWall rooms.
-			s.createRoom(command.Actor, command.X)
-		case <-s.stop:
-			s.Shutdown()
-			return
+		case now := <-reaper.C:
+			s.reapRooms(now, emptySince)
+		case <-ctx.Done():
+			timeout := s.ShutdownTimeout
+			if timeout <= 0 {
+				timeout = DefaultShutdownTimeout
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			return s.Shutdown(shutdownCtx)
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+// Listen will start listening on Addr. Run will call Listen by itself, but it
+// can be called before Run when listener address must be known before
+// starting the server e.g. when Addr is ":0".
+func (s *Server) Listen() error {
+	s.init()
+
+	s.listenMtx.Lock()
+	defer s.listenMtx.Unlock()
+
+	select {
+	case <-s.done:
+		return ErrServerClosed
+	default:
+	}
+
+	if s.listener != nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	log.Printf("listening on %s", listener.Addr())
+
+	go s.accept(listener)
+	return nil
+}
+
+// ListenAddr will return address server is listening on. Nil is returned if
+// server is not listening yet.
+func (s *Server) ListenAddr() net.Addr {
+	s.listenMtx.Lock()
+	defer s.listenMtx.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Shutdown will stop server gracefully. Server stops accepting new
+// connections, notifies and disconnects all connected clients and stops all
+// registered rooms. Shutdown will block until all rooms are stopped or ctx is
+// done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.init()
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+
+	// stop accepting new connections.
+	s.listenMtx.Lock()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.listenMtx.Unlock()
+
+	// notify clients that this is the end of the world.
+	for _, client := range s.connectedClients() {
+		client.Notify("# server is shutting down\n")
+		client.Drop()
+	}
+
+	// stop all rooms and wait until room and zombie goroutines exit.
+	stopped := make(chan struct{})
+	go func() {
+		wg := sync.WaitGroup{}
+		for _, r := range s.roomList() {
+			wg.Add(1)
+			go func(room types.Room) {
+				defer wg.Done()
+				room.Stop()
+			}(r.Room)
 		}
+		wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RegisterRoomType will register room factory under given type name. Clients
+// can create rooms of registered types with `NEW <name> <type>` command. Type
+// names are case-insensitive. Server has `training` and `wall` room types
+// registered by default, but they can be overridden.
+func (s *Server) RegisterRoomType(name string, factory types.RoomFactory) {
+	s.init()
+	s.roomTypesMtx.Lock()
+	s.roomTypes[strings.ToLower(name)] = factory
+	s.roomTypesMtx.Unlock()
+}
+
+// RoomTypes will return sorted names of registered room types.
+func (s *Server) RoomTypes() (names []string) {
+	s.init()
+	s.roomTypesMtx.Lock()
+	for name := range s.roomTypes {
+		names = append(names, name)
 	}
+	s.roomTypesMtx.Unlock()
+	sort.Strings(names)
+	return
 }
 
-// Shutdown will stop server
-func (s *Server) Shutdown() {}
+// HandleCommand will register new command in server. Command can be used in
+// the lobby, in the rooms or in both places depending on command Scope. Events
+// produced by this command will be passed to handler instead of the room.
+// Error is returned if command name or alias is already taken.
+func (s *Server) HandleCommand(c types.Command, handler CommandHandler) error {
+	s.init()
+	if err := s.commands.Register(c); err != nil {
+		return err
+	}
+	eventType := c.Event
+	if eventType == "" {
+		eventType = strings.ToUpper(c.Name)
+	}
+	s.handlersMtx.Lock()
+	s.handlers[eventType] = handler
+	s.handlersMtx.Unlock()
+	return nil
+}
 
 // AddRoom will registers new room into server.
 func (s *Server) AddRoom(r types.ServerRoom) {
@@ -54,113 +299,731 @@ func (s *Server) AddRoom(r types.ServerRoom) {
 	s.roomsMtx.Unlock()
 }
 
-func (s *Server) init() error {
-	s.newClient = make(chan net.Conn)
-	s.stop = make(chan os.Signal)
-	s.command = make(chan types.Event)
-
-	signal.Notify(s.stop, killSignals...)
+func (s *Server) init() {
+	s.initOnce.Do(func() {
+		s.newClient = make(chan net.Conn)
+		s.done = make(chan struct{})
+		s.clients = make(map[*Client]types.Room)
+		s.names = make(map[string]*Client)
+		s.parked = make(map[string]chan *Client)
+		s.zombies = make(map[types.Player]bool)
+		s.roomTypes = map[string]types.RoomFactory{
+			"training": rooms.NewTrainingGrounds,
+			"wall":     rooms.NewTheWall,
+		}
+		s.commands = NewCommandSet()
+		s.handlers = map[string]CommandHandler{}
+		s.registerChat()
+	})
+}
 
-	// move default room to the lobby so it will be visible for players.
-	if s.DefaultRoom != nil {
-		s.AddRoom(types.ServerRoom{
-			Room:    s.DefaultRoom,
-			Default: true,
-		})
+// addDefaultRoom will move default room to the lobby so it will be visible for
+// players. Default room is skipped if it is already registered in Rooms.
+func (s *Server) addDefaultRoom() {
+	if s.DefaultRoom == nil {
+		return
 	}
-
-	return s.listen()
+	for _, r := range s.roomList() {
+		if r.Room == s.DefaultRoom {
+			return
+		}
+	}
+	s.AddRoom(types.ServerRoom{
+		Room:    s.DefaultRoom,
+		Default: true,
+	})
 }
 
 func (s *Server) startRooms() {
 	s.roomsMtx.Lock()
 	for _, r := range s.Rooms {
-		r.Room.Init()
+		if err := r.Room.Init(); err != nil {
+			log.Printf("cannot init room %s: %s", r.Room.Name(), err)
+		}
+		for _, zombie := range r.Zombies {
+			r.Room.AddZombie(zombie)
+		}
 		r.Room.Run()
 	}
 	s.roomsMtx.Unlock()
 }
 
-func (s *Server) createRoom(name string, world int64) {
-	log.Printf("creating new room")
-	room := &rooms.TheWall{}
-	room.Init()
+// handleCommand will handle commands that client sends to server from the
+// lobby.
+func (s *Server) handleCommand(client *Client, command types.Event) error {
+	switch command.Type {
+	case types.EventStart:
+		return s.claimName(client, command.Actor)
+	case types.EventList:
+		client.ShowRooms(s.lobby())
+	case types.EventWho:
+		r, ok := s.lookupRoom(command.Actor)
+		if !ok {
+			return errNoSuchRoom(command.Actor)
+		}
+		var names, zombies, spectators []string
+		for _, player := range r.Room.Players() {
+			names = append(names, player.Name())
+		}
+		if w, ok := r.Room.(types.Watchable); ok {
+			// players that play as zombies are attached to the room
+			// as spectators.
+			for _, spectator := range w.Spectators() {
+				if s.isZombie(spectator) {
+					zombies = append(zombies, spectator.Name())
+					continue
+				}
+				spectators = append(spectators, spectator.Name())
+			}
+		}
+		client.ShowPlayers(r.Room.Name(), names, zombies, spectators)
+	case types.EventInfo:
+		r, ok := s.lookupRoom(command.Actor)
+		if !ok {
+			return errNoSuchRoom(command.Actor)
+		}
+		client.ShowInfo(describeRoom(r))
+	case types.EventJoin:
+		room, err := s.findRoom(command.Actor)
+		if err != nil || len(command.Args) == 0 {
+			return err
+		}
+		if !asZombie(command.Args) {
+			return fmt.Errorf("use JOIN <room> AS ZOMBIE to play as zombie")
+		}
+		if !hauntable(room) {
+			return fmt.Errorf("room '%s' cannot be joined as zombie", room.Name())
+		}
+	case types.EventWatch:
+		room, err := s.findRoom(command.Actor)
+		if err != nil {
+			return err
+		}
+		if _, ok := room.(types.Watchable); !ok {
+			return fmt.Errorf("room '%s' cannot be watched", room.Name())
+		}
+	case types.EventResume:
+		return s.resume(client, command.Actor)
+	case types.EventNew:
+		kind := DefaultRoomType
+		var args []string
+		if len(command.Args) > 0 {
+			kind, args = command.Args[0], command.Args[1:]
+		}
+		opts, err := types.ParseRoomOptions(args)
+		if err != nil {
+			return err
+		}
+		return s.createRoom(command.Actor, kind, opts)
+	default:
+		if handler, ok := s.handler(command.Type); ok {
+			return handler(client, command)
+		}
+	}
+	return nil
+}
+
+// claimName will reserve player name for client. Names are unique in whole
+// server and case-insensitive. Name previously used by this client is
+// released.
+func (s *Server) claimName(client *Client, name string) error {
+	if err := types.ValidateName(name); err != nil {
+		return err
+	}
+	key := strings.ToLower(name)
+
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	if other, ok := s.names[key]; ok && other != client {
+		return types.ErrNameTaken(name)
+	}
+	s.releaseName(client)
+	s.names[key] = client
+	return nil
+}
+
+// releaseName will forget name used by client. clientsMtx must be held.
+func (s *Server) releaseName(client *Client) {
+	for name, c := range s.names {
+		if c == client {
+			delete(s.names, name)
+		}
+	}
+}
+
+// handler will return handler of command registered with HandleCommand.
+func (s *Server) handler(eventType string) (CommandHandler, bool) {
+	s.handlersMtx.Lock()
+	defer s.handlersMtx.Unlock()
+	handler, ok := s.handlers[eventType]
+	return handler, ok
+}
+
+// roomCommands will return commands that can be used in given room. Room can
+// add its own commands by implementing types.CommandProvider.
+func (s *Server) roomCommands(room types.Room) *CommandSet {
+	if provider, ok := room.(types.CommandProvider); ok {
+		return s.commands.With(provider.Commands()...)
+	}
+	return s.commands
+}
+
+// NewRoom will create room of registered type with given options. Room is not
+// started nor registered in server.
+func (s *Server) NewRoom(kind string, opts types.RoomOptions) (types.Room, error) {
+	s.init()
+	s.roomTypesMtx.Lock()
+	factory, ok := s.roomTypes[strings.ToLower(kind)]
+	s.roomTypesMtx.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown room type '%s'", strings.ToLower(kind))
+	}
+	return factory(opts)
+}
+
+// createRoom will create, start and register new room of given type.
+func (s *Server) createRoom(name, kind string, opts types.RoomOptions) error {
+	kind = strings.ToLower(kind)
+
+	// check early, so room is not built in vain. Checks are repeated
+	// when room is registered, because other client could create room
+	// in the meantime.
+	if err := s.canAddRoom(s.roomList(), name); err != nil {
+		return err
+	}
+
+	room, err := s.NewRoom(kind, opts)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("creating new %s room %s", kind, name)
 	room.SetName(name)
+	if err := room.Init(); err != nil {
+		return err
+	}
 	room.Run()
-	s.AddRoom(types.ServerRoom{
+
+	s.roomsMtx.Lock()
+	if err := s.canAddRoom(s.Rooms, name); err != nil {
+		s.roomsMtx.Unlock()
+		room.Stop()
+		return err
+	}
+	s.Rooms = append(s.Rooms, types.ServerRoom{
 		Room:    room,
 		Default: false,
+		Type:    kind,
 	})
+	s.roomsMtx.Unlock()
+	return nil
+}
+
+// canAddRoom will return error if room with given name cannot be added to
+// rooms because of MaxRooms limit or because the name is already taken.
+func (s *Server) canAddRoom(rooms []types.ServerRoom, name string) error {
+	if s.MaxRooms > 0 && len(rooms) >= s.MaxRooms {
+		return fmt.Errorf("room limit reached")
+	}
+	for _, r := range rooms {
+		if strings.EqualFold(r.Room.Name(), name) {
+			return fmt.Errorf("room '%s' already exists", name)
+		}
+	}
+	return nil
 }
 
 // acceptClient will be called when new connection appears in server.
 func (s *Server) acceptClient(c net.Conn) {
 	client := &Client{
-		Name: "unknown warrior",
-		Conn: c,
+		Conn:             c,
+		Commands:         s.commands,
+		QueueSize:        s.ClientQueueSize,
+		WriteTimeout:     s.ClientWriteTimeout,
+		SlowClientPolicy: s.SlowClientPolicy,
+		MaxLineLength:    s.MaxLineLength,
+		LobbyIdleTimeout: s.LobbyIdleTimeout,
+		GameIdleTimeout:  s.GameIdleTimeout,
+	}
+
+	s.clientsMtx.Lock()
+	select {
+	case <-s.done:
+		// server is shutting down, do not accept new clients anymore.
+		s.clientsMtx.Unlock()
+		client.Drop()
+		return
+	default:
+	}
+	if s.MaxClients > 0 && len(s.clients) >= s.MaxClients {
+		s.clientsMtx.Unlock()
+		client.Notify("# server is full, try again later\n")
+		client.Drop()
+		return
+	}
+	s.lastID++
+	client.id = strconv.FormatUint(s.lastID, 10)
+	// clients that have not started the game are named as guests, so
+	// they can be listed with `WHO` or chat from the lobby. Guest names
+	// cannot be claimed by players.
+	client.setName("guest#" + client.id)
+	s.clients[client] = nil
+	s.clientsMtx.Unlock()
+	log.Printf("client %s connected from %s", client.id, c.RemoteAddr())
+
+	defer func() {
+		if client.resumed {
+			// connection is used by resumed session now. Name claimed
+			// before resume is not used anymore.
+			s.clientsMtx.Lock()
+			delete(s.clients, client)
+			s.releaseName(client)
+			s.clientsMtx.Unlock()
+			return
+		}
+		client.Drop()
+		s.clientsMtx.Lock()
+		delete(s.clients, client)
+		s.releaseName(client)
+		s.dropped += client.Dropped()
+		s.clientsMtx.Unlock()
+		if dropped := client.Dropped(); dropped > 0 {
+			log.Printf("%d messages to client %s were dropped", dropped, client.id)
+		}
+	}()
+
+	for {
+		room := s.waitForRoom(client)
+		if room == nil {
+			return
+		}
+
+		var err error
+		commands := s.roomCommands(room)
+		if client.AsZombie() {
+			commands = commands.With(zombieCommands...)
+		}
+		client.Attach(commands)
+		s.setRoom(client, room)
+		if w, ok := room.(types.Watchable); ok && client.Watching() {
+			err = s.watch(client, room, w)
+		} else if client.AsZombie() && hauntable(room) {
+			err = s.haunt(client, room)
+		} else {
+			room.AddPlayer(client)
+			s.issueToken(client)
+			err = s.play(client, room)
+			client.Detach()
+			room.RemovePlayer(client)
+		}
+		s.setRoom(client, nil)
+		if err != nil {
+			return
+		}
+
+		// client left the room with `LEAVE` command, so return him
+		// to the lobby.
+		client.Notify("# you left room " + room.Name() + "\n")
+		client.ResetRoom()
+	}
+}
+
+// play will handle client events in the room until client leaves the room or
+// disconnects. If client disconnects and ResumeTimeout is set, player keeps
+// his place in the room until he resumes session from new connection or
+// ResumeTimeout passes. Clients that were idle for too long are not kept.
+func (s *Server) play(client *Client, room types.Room) error {
+	for {
+		err := client.Run(func(e types.Event) (bool, error) {
+			handler, ok := s.handler(e.Type)
+			if !ok {
+				return false, nil
+			}
+			return true, handler(client, e)
+		})
+		if err == nil || err == errDropped || isTimeout(err) || !s.waitForResume(client) {
+			return err
+		}
+		s.replay(client, room)
+	}
+}
+
+// watch will attach client to the room as spectator until client leaves the
+// room or disconnects. Spectators receive room events, but commands that
+// should be handled by the room are rejected.
+func (s *Server) watch(client *Client, room types.Room, w types.Watchable) error {
+	client.Notify("# you are watching " + room.Name() + ", type LEAVE to return to the lobby\n")
+	if snapshotter, ok := room.(types.Snapshotter); ok {
+		for _, e := range snapshotter.Snapshot() {
+			client.ProcessEvent(e)
+		}
+	}
+	w.AddSpectator(client)
+	err := client.Run(func(e types.Event) (bool, error) {
+		if handler, ok := s.handler(e.Type); ok {
+			return true, handler(client, e)
+		}
+		return true, types.ErrSpectator
+	})
+	client.Detach()
+	w.RemoveSpectator(client)
+	return err
+}
+
+// issueToken will send resume token to client. Token is generated once per
+// client. Nothing is sent if ResumeTimeout is not set.
+func (s *Server) issueToken(client *Client) {
+	if s.ResumeTimeout <= 0 {
+		return
+	}
+	if client.token == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			log.Printf("cannot generate resume token: %s", err)
+			return
+		}
+		client.token = hex.EncodeToString(b)
+	}
+	client.ProcessEvent(types.Event{Type: types.EventToken, Actor: client.token})
+}
+
+// waitForResume will keep disconnected client until he resumes session with
+// `RESUME <token>` command from new connection. True is returned if session
+// was resumed and client can continue the game.
+func (s *Server) waitForResume(client *Client) bool {
+	if s.ResumeTimeout <= 0 || client.token == "" {
+		return false
+	}
+	// connection is lost, so room events are not queued until client
+	// resumes session. Snapshot of the room is sent after resume.
+	client.hangUp()
+	resume := make(chan *Client, 1)
+	s.clientsMtx.Lock()
+	s.parked[client.token] = resume
+	s.clientsMtx.Unlock()
+	log.Printf("client %s disconnected, waiting %s for resume", client.id, s.ResumeTimeout)
+
+	timer := time.NewTimer(s.ResumeTimeout)
+	defer timer.Stop()
+	select {
+	case other := <-resume:
+		client.takeOver(other)
+		return true
+	case <-timer.C:
+	case <-s.done:
 	}
 
+	// session could be resumed while we were waiting for lock.
+	s.clientsMtx.Lock()
+	delete(s.parked, client.token)
+	s.clientsMtx.Unlock()
+	select {
+	case other := <-resume:
+		client.takeOver(other)
+		select {
+		case <-s.done:
+			return false
+		default:
+			return true
+		}
+	default:
+	}
+	log.Printf("client %s did not resume session", client.id)
+	return false
+}
+
+// resume will hand connection of client over to disconnected session with
+// given token.
+func (s *Server) resume(client *Client, token string) error {
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	resume, ok := s.parked[token]
+	if !ok {
+		return types.ErrBadToken
+	}
+	delete(s.parked, token)
+	client.resumed = true
+	resume <- client
+	return nil
+}
+
+// replay will welcome player back and send current state of the room if room
+// implements types.Snapshotter.
+func (s *Server) replay(client *Client, room types.Room) {
+	log.Printf("client %s resumed session", client.id)
+	client.Notify("# welcome back " + client.Name() + ", you are in " + room.Name() + "\n")
+	if snapshotter, ok := room.(types.Snapshotter); ok {
+		for _, e := range snapshotter.Snapshot() {
+			client.ProcessEvent(e)
+		}
+	}
+}
+
+// waitForRoom will keep client in the lobby until client starts the game. Room
+// where client wants to join will be returned. Nil is returned if client
+// disconnects.
+func (s *Server) waitForRoom(client *Client) types.Room {
 	// show possible rooms to client. Client can select where he wants to
 	// join with `JOIN` command.
-	client.ShowLobby(s.lobby())
+	client.ShowLobby(s.lobby(), s.RoomTypes())
 
-	// wait until client produces EventStart. Also client can select room
-	// where he wants to join or even create new room with `NEW` command.
-	// So `JOIN`, `NEW` and `START` commands will be processed here.
-	if err := client.WaitForStart(s.command); err != nil {
-		log.Printf("WaitForStart returned error: %s", err)
-		return
+	var room types.Room
+	for room == nil {
+		// wait until client produces EventStart. Also client can select
+		// room where he wants to join or even create new room with
+		// `NEW` command. So `JOIN`, `NEW`, lobby commands and `START`
+		// commands will be processed here.
+		err := client.WaitForStart(func(command types.Event) error {
+			return s.handleCommand(client, command)
+		})
+		if err != nil {
+			log.Printf("WaitForStart returned error: %s", err)
+			return nil
+		}
+		if client.resumed {
+			// connection was handed over to resumed session.
+			return nil
+		}
+
+		// selected room could be finished or removed while client was
+		// in the lobby. In such case client stays in the lobby.
+		if room, err = s.findRoom(client.SelectedRoom()); err != nil {
+			client.Notify("# " + err.Error() + "\n")
+			client.ResetRoom()
+		}
 	}
+	return room
+}
 
-	// we expect that client selected room with JOIN command. If no, then
-	// client will be forced to join to default room.
-	if s.DefaultRoom != nil && client.SelectedRoom() == "" {
+// findRoom will return room that client can join by room name. We expect that
+// client selected room with JOIN command. If no, then client will be forced to
+// join to default room.
+func (s *Server) findRoom(name string) (types.Room, error) {
+	var room types.Room
+	if name == "" {
+		if s.DefaultRoom == nil {
+			return nil, fmt.Errorf("there is no default room, please select room")
+		}
 		log.Printf("force joined")
-		s.DefaultRoom.AddPlayer(client)
-		go client.Run()
+		room = s.DefaultRoom
+	} else if r, ok := s.lookupRoom(name); ok {
+		room = r.Room
+	}
+
+	if room != nil && room.State() == types.RoomFinished {
+		s.restartDefaultRoom(room)
+	}
+
+	switch {
+	case room == nil:
+		return nil, errNoSuchRoom(name)
+	case room.State() == types.RoomFinished:
+		return nil, fmt.Errorf("room '%s' is finished", room.Name())
+	}
+	return room, nil
+}
+
+// restartDefaultRoom will start new round in finished default room when all
+// players have left it. Default room is never removed, so otherwise nobody
+// could join it again.
+func (s *Server) restartDefaultRoom(room types.Room) {
+	if room != s.DefaultRoom || len(room.Players()) > 0 {
+		return
+	}
+	restarter, ok := room.(types.Restarter)
+	if !ok {
+		return
+	}
+	if err := restarter.Restart(); err != nil {
+		log.Printf("cannot restart default room %s: %s", room.Name(), err)
 		return
 	}
+	log.Printf("restarted finished default room %s", room.Name())
+}
 
-	// join client to required room.
-	s.roomsMtx.Lock()
-	for _, r := range s.Rooms {
-		if r.Room.Name() == client.SelectedRoom() {
-			r.Room.AddPlayer(client)
-			go client.Run()
-			break
+// lookupRoom will return registered room by name. Room names are
+// case-insensitive.
+func (s *Server) lookupRoom(name string) (types.ServerRoom, bool) {
+	for _, r := range s.roomList() {
+		if strings.EqualFold(r.Room.Name(), name) {
+			return r, true
 		}
 	}
-	s.roomsMtx.Unlock()
+	return types.ServerRoom{}, false
 }
 
-func (s *Server) listen() error {
-	listener, err := net.Listen("tcp", s.Addr)
-	if err != nil {
-		return err
+// errNoSuchRoom will return protocol error for room that does not exist.
+func errNoSuchRoom(name string) error {
+	return &types.ProtocolError{
+		Code: types.ErrCodeNoSuchRoom,
+		Msg:  fmt.Sprintf("room '%s' does not exist", name),
 	}
-	log.Printf("listening on %s", s.Addr)
-	go func() {
-		for {
-			conn, err := listener.Accept()
-			if err != nil {
-				log.Printf("cannot accept connection: %s", err)
-				continue
+}
+
+// reapInterval will return how often finished and idle rooms are removed.
+func (s *Server) reapInterval() time.Duration {
+	if s.ReapInterval <= 0 {
+		return DefaultReapInterval
+	}
+	return s.ReapInterval
+}
+
+// reapRooms will remove finished rooms without players and rooms that are
+// empty longer than RoomIdleTimeout. Spectators and zombie players are counted
+// as occupants, so watched or haunted room is not removed. Default room is never removed. emptySince holds
+// time since when room is empty.
+func (s *Server) reapRooms(now time.Time, emptySince map[types.Room]time.Time) {
+	for _, r := range s.roomList() {
+		if r.Default {
+			continue
+		}
+		if occupied(r.Room) || s.haunted(r.Room) {
+			delete(emptySince, r.Room)
+			continue
+		}
+		if _, ok := emptySince[r.Room]; !ok {
+			emptySince[r.Room] = now
+		}
+		idle := now.Sub(emptySince[r.Room])
+
+		switch {
+		case r.Room.State() == types.RoomFinished:
+			log.Printf("removing finished room %s", r.Room.Name())
+		case s.RoomIdleTimeout > 0 && idle >= s.RoomIdleTimeout:
+			log.Printf("removing room %s, empty for %s", r.Room.Name(), idle)
+		default:
+			continue
+		}
+
+		delete(emptySince, r.Room)
+		s.removeRoom(r.Room)
+		r.Room.Stop()
+	}
+}
+
+// occupied will return true if there are players or spectators in the room.
+func occupied(room types.Room) bool {
+	if len(room.Players()) > 0 {
+		return true
+	}
+	w, ok := room.(types.Watchable)
+	return ok && len(w.Spectators()) > 0
+}
+
+// removeRoom will unregister room from server.
+func (s *Server) removeRoom(room types.Room) {
+	s.roomsMtx.Lock()
+	defer s.roomsMtx.Unlock()
+	for i, r := range s.Rooms {
+		if r.Room == room {
+			s.Rooms = append(s.Rooms[:i], s.Rooms[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Server) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return
+			default:
 			}
-			log.Printf("accepted connection from %s", conn.RemoteAddr())
-			s.newClient <- conn
+			log.Printf("cannot accept connection: %s", err)
+			continue
 		}
-	}()
-	return nil
+		log.Printf("accepted connection from %s", conn.RemoteAddr())
+		select {
+		case s.newClient <- conn:
+		case <-s.done:
+			conn.Close()
+			return
+		}
+	}
 }
 
-func (s *Server) lobby() (lobby []types.Lobby) {
+// DroppedMessages will return how many messages to slow clients were dropped
+// since server started.
+func (s *Server) DroppedMessages() int {
+	s.init()
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	dropped := s.dropped
+	for c := range s.clients {
+		dropped += c.Dropped()
+	}
+	return dropped
+}
+
+// setRoom will remember in which room client is. Nil room means that client
+// is in the lobby.
+func (s *Server) setRoom(client *Client, room types.Room) {
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	if _, ok := s.clients[client]; ok {
+		s.clients[client] = room
+	}
+}
+
+// roomOf will return room where client is. Nil is returned if client is in
+// the lobby.
+func (s *Server) roomOf(client *Client) types.Room {
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	return s.clients[client]
+}
+
+// lobbyClients will return clients that are in the lobby.
+func (s *Server) lobbyClients() (clients []*Client) {
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	for c, room := range s.clients {
+		if room == nil {
+			clients = append(clients, c)
+		}
+	}
+	return
+}
+
+func (s *Server) connectedClients() (clients []*Client) {
+	s.clientsMtx.Lock()
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.clientsMtx.Unlock()
+	return
+}
+
+func (s *Server) roomList() []types.ServerRoom {
 	s.roomsMtx.Lock()
-	for _, r := range s.Rooms {
-		lobby = append(lobby, types.Lobby{
-			Name:    r.Room.Name(),
-			Default: r.Default,
-		})
+	defer s.roomsMtx.Unlock()
+	return append([]types.ServerRoom(nil), s.Rooms...)
+}
+
+func (s *Server) lobby() (lobby []types.Lobby) {
+	for _, r := range s.roomList() {
+		lobby = append(lobby, describeRoom(r))
 	}
-	s.roomsMtx.Unlock()
 	return
 }
+
+// describeRoom will collect live room details shown in the lobby.
+func describeRoom(r types.ServerRoom) types.Lobby {
+	l := types.Lobby{
+		Name:    r.Room.Name(),
+		Default: r.Default,
+		Type:    r.Type,
+		State:   r.Room.State(),
+		Players: len(r.Room.Players()),
+		Zombies: r.Room.ZombieCount(),
+	}
+	if scorer, ok := r.Room.(types.Scorer); ok {
+		l.Scores = scorer.Scores()
+	}
+	if describer, ok := r.Room.(types.Describer); ok {
+		l.Rules = describer.Rules()
+	}
+	return l
+}
@@ -0,0 +1,770 @@
+package engine_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine"
+	"github.com/sheirys/zombebattle/engine/rooms"
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+func TestServerShutdown(t *testing.T) {
+	server := &engine.Server{
+		Addr: "127.0.0.1:0",
+		DefaultRoom: &rooms.TrainingGrounds{
+			Zombies: []types.Zombie{
+				&zombies.Dummy{},
+			},
+		},
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	result := make(chan error)
+	go func() {
+		result <- server.Run(context.Background())
+	}()
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+
+	// join default room, so client is attached to the room when server
+	// shuts down.
+	conn.Write([]byte("start tester\n"))
+	reader := bufio.NewReader(conn)
+	waitForLine(t, reader, "# TRAINING-GROUNDS")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected shutdown error: %s", err)
+	}
+
+	waitForLine(t, reader, "# server is shutting down")
+
+	if err := <-result; err != nil {
+		t.Errorf("unexpected run error: %s", err)
+	}
+
+	if _, err := net.Dial("tcp", server.ListenAddr().String()); err == nil {
+		t.Errorf("server should not accept connections after shutdown")
+	}
+}
+
+func TestServerRunContext(t *testing.T) {
+	server := &engine.Server{
+		Addr:        "127.0.0.1:0",
+		DefaultRoom: &rooms.TheWall{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- server.Run(ctx)
+	}()
+
+	cancel()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("unexpected run error: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("server did not stop after context was canceled")
+	}
+}
+
+func TestServerNewRoomType(t *testing.T) {
+	server := &engine.Server{
+		Addr: "127.0.0.1:0",
+	}
+	server.RegisterRoomType("Arena", rooms.NewTrainingGrounds)
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	waitForLine(t, reader, "# new world. Available types: arena, training, wall")
+
+	conn.Write([]byte("new world1 unknown\n"))
+	waitForLine(t, reader, "# unknown room type 'unknown'")
+
+	conn.Write([]byte("new world1 arena\n"))
+	waitForLine(t, reader, "# created room world1")
+	conn.Write([]byte("join WORLD1\n"))
+	waitForLine(t, reader, "# selected room WORLD1")
+	conn.Write([]byte("start tester\n"))
+	waitForLine(t, reader, "# world1")
+}
+
+func TestServerNewRoomConcurrent(t *testing.T) {
+	server := &engine.Server{
+		Addr:     "127.0.0.1:0",
+		MaxRooms: 3,
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	// every client tries to create the same room and a room of its own.
+	// Only one room can be named world and room limit must not be exceeded.
+	clients := 8
+	done := make(chan bool)
+	for i := 0; i < clients; i++ {
+		go func(i int) {
+			defer func() { done <- true }()
+			conn, err := net.Dial("tcp", server.ListenAddr().String())
+			if err != nil {
+				t.Errorf("cannot connect to server: %s", err)
+				return
+			}
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+
+			conn.Write([]byte("new world training\n"))
+			conn.Write([]byte(fmt.Sprintf("new room%d training\n", i)))
+			for replies := 0; replies < 2; {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					t.Errorf("cannot read reply: %s", err)
+					return
+				}
+				if strings.HasPrefix(line, "# created room") ||
+					strings.HasPrefix(line, "# room") {
+					replies++
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < clients; i++ {
+		<-done
+	}
+
+	if len(server.Rooms) > server.MaxRooms {
+		t.Errorf("expected at most %d rooms, got %d", server.MaxRooms, len(server.Rooms))
+	}
+	names := map[string]bool{}
+	for _, r := range server.Rooms {
+		if names[r.Room.Name()] {
+			t.Errorf("room '%s' was created more than once", r.Room.Name())
+		}
+		names[r.Room.Name()] = true
+	}
+}
+
+func TestServerReapIdleRoom(t *testing.T) {
+	server := &engine.Server{
+		Addr:            "127.0.0.1:0",
+		RoomIdleTimeout: time.Millisecond,
+		ReapInterval:    10 * time.Millisecond,
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	conn.Write([]byte("new world1 training\n"))
+	waitForLine(t, reader, "# created room world1")
+
+	// watched room should not be removed.
+	spectator, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	spectatorReader := bufio.NewReader(spectator)
+	waitForLine(t, spectatorReader, "# new world.")
+	spectator.Write([]byte("watch world1\n"))
+	waitForLine(t, spectatorReader, "# you are watching world1")
+	time.Sleep(100 * time.Millisecond)
+	conn.Write([]byte("join world1\n"))
+	waitForLine(t, reader, "# selected room world1")
+	spectator.Close()
+
+	// room haunted by zombie player should not be removed too.
+	zombie, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	zombieReader := bufio.NewReader(zombie)
+	waitForLine(t, zombieReader, "# new world.")
+	zombie.Write([]byte("join world1 as zombie\nstart zed\n"))
+	waitForLine(t, zombieReader, "# you are zombie zed in world1")
+	time.Sleep(100 * time.Millisecond)
+	conn.Write([]byte("join world1\n"))
+	waitForLine(t, reader, "# selected room world1")
+	zombie.Close()
+
+	// nobody joins this room, so it should be removed by server.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		conn.Write([]byte("join world1\n"))
+		line := waitForLine(t, reader, "")
+		if strings.HasPrefix(line, "ERR NO_SUCH_ROOM room 'world1' does not exist") {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("idle room was not removed")
+}
+
+func TestServerLeave(t *testing.T) {
+	server := &engine.Server{
+		Addr: "127.0.0.1:0",
+		DefaultRoom: &rooms.TrainingGrounds{
+			Zombies: []types.Zombie{
+				&zombies.Dummy{},
+			},
+		},
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	conn.Write([]byte("shoot 1 1\n"))
+	waitForLine(t, reader, "ERR WRONG_CONTEXT")
+	conn.Write([]byte("start tester\n"))
+	waitForLine(t, reader, "# TRAINING-GROUNDS")
+	conn.Write([]byte("list\n"))
+	waitForLine(t, reader, "ERR WRONG_CONTEXT")
+
+	conn.Write([]byte("leave\n"))
+	waitForLine(t, reader, "# you left room TRAINING-GROUNDS")
+	waitForLine(t, reader, "#    TRAINING-GROUNDS waiting, 0 players, 1 zombies (default)")
+
+	conn.Write([]byte("new world1 wall\n"))
+	waitForLine(t, reader, "# created room world1")
+	conn.Write([]byte("join WORLD1\n"))
+	waitForLine(t, reader, "# selected room WORLD1")
+	conn.Write([]byte("start tester\n"))
+	waitForLine(t, reader, "# world1")
+}
+
+func TestServerRestartDefaultRoom(t *testing.T) {
+	server := &engine.Server{
+		Addr: "127.0.0.1:0",
+		DefaultRoom: &rooms.TheWall{
+			Options: rooms.TheWallOptions{
+				Width:            2,
+				Height:           3,
+				PlayerScore:      5,
+				ZombieScore:      1,
+				CrawlerTick:      rooms.TheWallMinTick,
+				ZombiesPerPlayer: 1,
+			},
+		},
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// zombie reaches the wall after few steps and game is over.
+	conn.Write([]byte("start one\n"))
+	waitForLine(t, reader, "GAMEOVER zombies")
+	conn.Write([]byte("leave\n"))
+	waitForLine(t, reader, "# you left room THE-WALL")
+
+	// finished default room without players should start new round for
+	// next players, even when they join at the same time.
+	var readers []*bufio.Reader
+	for _, name := range []string{"two", "three", "four"} {
+		next, err := net.Dial("tcp", server.ListenAddr().String())
+		if err != nil {
+			t.Fatalf("cannot connect to server: %s", err)
+		}
+		defer next.Close()
+		nextReader := bufio.NewReader(next)
+		waitForLine(t, nextReader, "# new world.")
+		go next.Write([]byte("start " + name + "\n"))
+		readers = append(readers, nextReader)
+	}
+	for _, nextReader := range readers {
+		waitForLine(t, nextReader, "# THE-WALL")
+	}
+}
+
+func TestServerLobbyCommands(t *testing.T) {
+	server := &engine.Server{
+		Addr:        "127.0.0.1:0",
+		DefaultRoom: &rooms.TheWall{},
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	player, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer player.Close()
+	player.Write([]byte("start alice\n"))
+	waitForLine(t, bufio.NewReader(player), "# THE-WALL")
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	waitForLine(t, reader, "# new world.")
+
+	conn.Write([]byte("list\n"))
+	line := waitForLine(t, reader, "#    THE-WALL")
+	if !strings.HasPrefix(line, "#    THE-WALL running, 1 players, 1 zombies, players 0/5, zombies 0/5 (default)") {
+		t.Errorf("unexpected room line: %s", line)
+	}
+
+	conn.Write([]byte("who the-wall\n"))
+	waitForLine(t, reader, "# players in THE-WALL: alice")
+
+	conn.Write([]byte("info the-wall\n"))
+	waitForLine(t, reader, "# Rules: map 30x10")
+
+	conn.Write([]byte("who unknown\n"))
+	waitForLine(t, reader, "ERR NO_SUCH_ROOM room 'unknown' does not exist")
+
+	conn.Write([]byte("dance\n"))
+	waitForLine(t, reader, "ERR UNKNOWN_COMMAND unknown command")
+}
+
+func TestServerHandleCommand(t *testing.T) {
+	server := &engine.Server{
+		Addr: "127.0.0.1:0",
+		DefaultRoom: &rooms.TrainingGrounds{
+			Zombies: []types.Zombie{
+				&zombies.Dummy{},
+			},
+		},
+	}
+	wave := types.Command{
+		Name:  "WAVE",
+		Args:  []types.Arg{{Name: "hand", Optional: true}},
+		Help:  "wave to everyone",
+		Scope: types.ScopeLobby | types.ScopeRoom,
+	}
+	err := server.HandleCommand(wave, func(p types.Player, e types.Event) error {
+		p.Notify("# " + p.Name() + " waves " + strings.Join(e.Args, " ") + "\n")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("cannot register command: %s", err)
+	}
+	if err := server.HandleCommand(types.Command{Name: "JOIN"}, nil); err == nil {
+		t.Errorf("expected error when registering built-in command")
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	waitForLine(t, reader, "# new world.")
+
+	conn.Write([]byte("help\n"))
+	waitForLine(t, reader, "#    WAVE [hand]")
+
+	conn.Write([]byte("wave left\n"))
+	waitForLine(t, reader, "# guest#1 waves left")
+
+	conn.Write([]byte("start tester\n"))
+	waitForLine(t, reader, "# TRAINING-GROUNDS")
+	conn.Write([]byte("wave\n"))
+	waitForLine(t, reader, "# tester waves")
+}
+
+func TestServerUniqueNames(t *testing.T) {
+	server := &engine.Server{
+		Addr: "127.0.0.1:0",
+		DefaultRoom: &rooms.TrainingGrounds{
+			Zombies: []types.Zombie{
+				&zombies.Dummy{},
+			},
+		},
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	bob, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer bob.Close()
+	bob.Write([]byte("start bob\n"))
+	waitForLine(t, bufio.NewReader(bob), "# TRAINING-GROUNDS")
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	waitForLine(t, reader, "# new world.")
+
+	conn.Write([]byte("start BOB\n"))
+	waitForLine(t, reader, "ERR NAME_TAKEN name 'BOB' is already taken")
+
+	conn.Write([]byte("start bob!\n"))
+	waitForLine(t, reader, "ERR BAD_NAME")
+
+	conn.Write([]byte("start bobby\n"))
+	waitForLine(t, reader, "# TRAINING-GROUNDS")
+}
+
+func TestServerPipelinedInput(t *testing.T) {
+	server := &engine.Server{
+		Addr: "127.0.0.1:0",
+		DefaultRoom: &rooms.TrainingGrounds{
+			Zombies: []types.Zombie{
+				&zombies.Dummy{},
+			},
+		},
+		MaxLineLength: 32,
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// all commands are sent in one write, none of them should be lost.
+	conn.Write([]byte("\xff\xfd\x01who training-grounds\r\n" +
+		strings.Repeat("x", 64) + "\r\n" +
+		"join training-grounds\r\n" +
+		"start bob\r\n" +
+		"start bob\r\n"))
+	waitForLine(t, reader, "# there are no players in TRAINING-GROUNDS")
+	waitForLine(t, reader, "ERR LINE_TOO_LONG line is too long")
+	waitForLine(t, reader, "# selected room training-grounds")
+	waitForLine(t, reader, "# TRAINING-GROUNDS")
+	waitForLine(t, reader, "ERR WRONG_CONTEXT")
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	room := &rooms.TrainingGrounds{
+		Zombies: []types.Zombie{
+			&zombies.Dummy{},
+		},
+	}
+	server := &engine.Server{
+		Addr:             "127.0.0.1:0",
+		DefaultRoom:      room,
+		LobbyIdleTimeout: time.Second,
+		GameIdleTimeout:  300 * time.Millisecond,
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	waitForLine(t, reader, "# new world.")
+
+	conn.Write([]byte("ping lobby\n"))
+	waitForLine(t, reader, "PONG lobby")
+
+	conn.Write([]byte("start sleepy\n"))
+	waitForLine(t, reader, "# TRAINING-GROUNDS")
+
+	// pings should keep player in the room.
+	for i := 0; i < 3; i++ {
+		time.Sleep(150 * time.Millisecond)
+		conn.Write([]byte("ping\n"))
+		waitForLine(t, reader, "PONG")
+	}
+	if len(room.Players()) != 1 {
+		t.Fatalf("expected player to stay in the room")
+	}
+
+	waitForLine(t, reader, "# you were idle for too long, disconnecting")
+	if _, err := reader.ReadString('\n'); err == nil {
+		t.Errorf("expected connection to be closed")
+	}
+	if len(room.Players()) != 0 {
+		t.Errorf("expected idle player to be removed from the room")
+	}
+}
+
+func TestServerResume(t *testing.T) {
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:          10,
+			Height:         3,
+			PlayerScore:    5,
+			ZombieScore:    5,
+			CrawlerTick:    time.Hour,
+			InitialZombies: 1,
+		},
+	}
+	server := &engine.Server{
+		Addr:          "127.0.0.1:0",
+		DefaultRoom:   room,
+		ResumeTimeout: time.Second,
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	conn.Write([]byte("start bob\n"))
+	token := strings.TrimSpace(strings.TrimPrefix(waitForLine(t, bufio.NewReader(conn), "TOKEN "), "TOKEN "))
+	conn.Close()
+
+	conn, err = net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	waitForLine(t, reader, "# new world.")
+
+	conn.Write([]byte("resume wrong\n"))
+	waitForLine(t, reader, "ERR BAD_TOKEN")
+
+	// connection can play under other name before resuming the session.
+	conn.Write([]byte("start alice\n"))
+	waitForLine(t, reader, "# THE-WALL")
+	conn.Write([]byte("leave\n"))
+	waitForLine(t, reader, "# you left room THE-WALL")
+
+	// server could still be unaware that first connection is closed.
+	for resumed := false; !resumed; {
+		conn.Write([]byte("resume " + token + "\n"))
+		for {
+			line := waitForLine(t, reader, "")
+			if strings.HasPrefix(line, "ERR BAD_TOKEN") {
+				time.Sleep(10 * time.Millisecond)
+				break
+			}
+			if strings.HasPrefix(line, "# welcome back bob, you are in THE-WALL") {
+				resumed = true
+				break
+			}
+		}
+	}
+	waitForLine(t, reader, "WALK ")
+	waitForLine(t, reader, "SCORE players 0 5")
+	waitForLine(t, reader, "SCORE zombies 0 5")
+
+	conn.Write([]byte("score\n"))
+	waitForLine(t, reader, "#    bob")
+	if players := room.Players(); len(players) != 1 || players[0].Name() != "bob" {
+		t.Fatalf("expected bob to keep his place in the room, got: %v", players)
+	}
+
+	// name used before resume should be released.
+	other, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer other.Close()
+	otherReader := bufio.NewReader(other)
+	waitForLine(t, otherReader, "# new world.")
+	other.Write([]byte("start alice\nleave\n"))
+	waitForLine(t, otherReader, "# THE-WALL")
+	waitForLine(t, otherReader, "# you left room THE-WALL")
+
+	// player should be removed when he does not return in time.
+	conn.Close()
+	time.Sleep(1500 * time.Millisecond)
+	if players := room.Players(); len(players) != 0 {
+		t.Errorf("expected bob to be removed from the room, got: %v", players)
+	}
+}
+
+func TestServerWatch(t *testing.T) {
+	room := &rooms.TheWall{
+		Options: rooms.TheWallOptions{
+			Width:            10,
+			Height:           3,
+			PlayerScore:      5,
+			ZombieScore:      5,
+			CrawlerTick:      time.Hour,
+			ZombiesPerPlayer: 1,
+		},
+	}
+	server := &engine.Server{
+		Addr:        "127.0.0.1:0",
+		DefaultRoom: room,
+	}
+
+	if err := server.Listen(); err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+
+	player, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer player.Close()
+	playerReader := bufio.NewReader(player)
+	player.Write([]byte("start alice\n"))
+	waitForLine(t, playerReader, "# THE-WALL")
+
+	spectator, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer spectator.Close()
+	reader := bufio.NewReader(spectator)
+	waitForLine(t, reader, "# new world.")
+
+	spectator.Write([]byte("watch the-wall\n"))
+	waitForLine(t, reader, "# you are watching THE-WALL")
+	waitForLine(t, reader, "SCORE zombies 0 5")
+
+	spectator.Write([]byte("shoot 1 1\n"))
+	waitForLine(t, reader, "ERR SPECTATOR")
+	if count := room.ZombieCount(); count != 1 {
+		t.Errorf("spectator should not spawn crawlers: got %d zombies, want: 1", count)
+	}
+
+	player.Write([]byte("shoot 0 0\n"))
+	waitForLine(t, reader, "BOOM alice 0 []")
+
+	conn, err := net.Dial("tcp", server.ListenAddr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	lobby := bufio.NewReader(conn)
+	waitForLine(t, lobby, "# new world.")
+	conn.Write([]byte("who the-wall\n"))
+	waitForLine(t, lobby, "# players in THE-WALL: alice")
+	waitForLine(t, lobby, "# spectators in THE-WALL: guest#2")
+
+	spectator.Write([]byte("leave\n"))
+	waitForLine(t, reader, "# you left room THE-WALL")
+	conn.Write([]byte("who the-wall\nping\n"))
+	waitForLine(t, lobby, "# players in THE-WALL: alice")
+	if line, _ := lobby.ReadString('\n'); line != "PONG\n" {
+		t.Errorf("spectator should be removed from the room, got: %s", line)
+	}
+}
+
+// waitForLine will read lines from reader until line with given prefix
+// appears.
+func waitForLine(t *testing.T, r *bufio.Reader, prefix string) string {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("expected line '%s', got error: %s", prefix, err)
+		}
+		if strings.HasPrefix(line, prefix) {
+			return line
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Scope defines where command can be used.
+type Scope int
+
+// Possible command scopes. Command can be valid in both scopes.
+const (
+	ScopeLobby Scope = 1 << iota // client has not started the game yet.
+	ScopeRoom                    // client is playing in the room.
+)
+
+// ArgKind defines what value command argument holds.
+type ArgKind int
+
+// Possible argument kinds.
+const (
+	ArgString ArgKind = iota // any word.
+	ArgInt                   // integer number.
+	ArgCoord                 // map coordinate, integer that is not negative.
+)
+
+// Arg describes one command argument. Optional arguments can be omitted, but
+// only after all required arguments. Variadic argument can be only the last
+// one and takes all remaining words, even none.
+type Arg struct {
+	Name     string
+	Kind     ArgKind
+	Optional bool
+	Variadic bool
+}
+
+// String will return argument usage e.g. `<x>`, `[type]` or `[key=value...]`.
+func (a Arg) String() string {
+	switch {
+	case a.Variadic:
+		return "[" + a.Name + "...]"
+	case a.Optional:
+		return "[" + a.Name + "]"
+	}
+	return "<" + a.Name + ">"
+}
+
+// Command describes command that client can type. Commands are registered in
+// server, so server, lobby and rooms can introduce their own commands. Event
+// is type of event produced by this command. If Event is empty, command Name
+// will be used as event type.
+type Command struct {
+	Name    string
+	Aliases []string
+	Args    []Arg
+	Help    string
+	Scope   Scope
+	Event   string
+}
+
+// CommandProvider can be implemented by room that wants to introduce its own
+// commands. These commands can be used only by players inside this room and
+// events produced by them are passed to the room.
+type CommandProvider interface {
+	Commands() []Command
+}
+
+// Usage will return command syntax e.g. `SHOOT <x> <y>`.
+func (c Command) Usage() string {
+	usage := c.Name
+	for _, arg := range c.Args {
+		usage += " " + arg.String()
+	}
+	return usage
+}
+
+// Parse will produce event from command arguments. Arguments are passed
+// without command name itself. Arguments are mapped into event this way:
+// first required string argument is stored as Actor, first two integer
+// arguments are stored as X and Y, all other arguments are stored as Args.
+func (c Command) Parse(args []string) (Event, error) {
+	if !c.acceptsArgs(len(args)) {
+		return Event{}, ErrWrongArity
+	}
+
+	event := Event{Type: c.Event}
+	if event.Type == "" {
+		event.Type = c.Name
+	}
+
+	ints := 0
+	for i, value := range args {
+		arg := c.Args[len(c.Args)-1]
+		if i < len(c.Args) {
+			arg = c.Args[i]
+		}
+
+		switch arg.Kind {
+		case ArgInt, ArgCoord:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return Event{}, ErrNotANumber
+			}
+			if arg.Kind == ArgCoord && n < 0 {
+				return Event{}, ErrOutOfRange
+			}
+			switch ints {
+			case 0:
+				event.X = n
+			case 1:
+				event.Y = n
+			default:
+				event.Args = append(event.Args, value)
+			}
+			ints++
+		default:
+			if event.Actor == "" && !arg.Optional && !arg.Variadic {
+				event.Actor = value
+				continue
+			}
+			event.Args = append(event.Args, value)
+		}
+	}
+	return event, nil
+}
+
+// acceptsArgs will return true if command accepts given argument count.
+func (c Command) acceptsArgs(count int) bool {
+	required := 0
+	for _, arg := range c.Args {
+		if arg.Variadic {
+			return count >= required
+		}
+		if !arg.Optional {
+			required++
+		}
+	}
+	return count >= required && count <= len(c.Args)
+}
+
+// Is will return true if given name is command name or alias. Names are
+// case-insensitive.
+func (c Command) Is(name string) bool {
+	if strings.EqualFold(c.Name, name) {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package types_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+func TestCommandParse(t *testing.T) {
+	move := types.Command{
+		Name: "MOVE",
+		Args: []types.Arg{
+			{Name: "zombie"},
+			{Name: "dx", Kind: types.ArgInt},
+			{Name: "dy", Kind: types.ArgInt},
+			{Name: "speed", Kind: types.ArgInt, Optional: true},
+		},
+	}
+	cast := types.Command{
+		Name:  "CAST",
+		Event: "SPELL",
+		Args: []types.Arg{
+			{Name: "spell"},
+			{Name: "x", Kind: types.ArgCoord, Optional: true},
+			{Name: "y", Kind: types.ArgCoord, Optional: true},
+			{Name: "words", Variadic: true},
+		},
+	}
+
+	testTable := []struct {
+		Command       types.Command
+		Args          []string
+		ExpectedEvent types.Event
+		ExpectedErr   error
+	}{
+		{
+			Command:       move,
+			Args:          []string{"Z1", "-1", "2"},
+			ExpectedEvent: types.Event{Type: "MOVE", Actor: "Z1", X: -1, Y: 2},
+		},
+		{
+			Command:       move,
+			Args:          []string{"Z1", "1", "2", "3"},
+			ExpectedEvent: types.Event{Type: "MOVE", Actor: "Z1", X: 1, Y: 2, Args: []string{"3"}},
+		},
+		{
+			Command:     move,
+			Args:        []string{"Z1", "1"},
+			ExpectedErr: types.ErrWrongArity,
+		},
+		{
+			Command:     move,
+			Args:        []string{"Z1", "1", "2", "3", "4"},
+			ExpectedErr: types.ErrWrongArity,
+		},
+		{
+			Command:     move,
+			Args:        []string{"Z1", "a", "2"},
+			ExpectedErr: types.ErrNotANumber,
+		},
+		{
+			Command:       cast,
+			Args:          []string{"FIREBALL"},
+			ExpectedEvent: types.Event{Type: "SPELL", Actor: "FIREBALL"},
+		},
+		{
+			Command:       cast,
+			Args:          []string{"FIREBALL", "1", "2", "HOCUS", "POCUS"},
+			ExpectedEvent: types.Event{Type: "SPELL", Actor: "FIREBALL", X: 1, Y: 2, Args: []string{"HOCUS", "POCUS"}},
+		},
+		{
+			Command:     cast,
+			Args:        []string{"FIREBALL", "-1", "2"},
+			ExpectedErr: types.ErrOutOfRange,
+		},
+		{
+			Command:     cast,
+			Args:        []string{},
+			ExpectedErr: types.ErrWrongArity,
+		},
+	}
+
+	for idx, c := range testTable {
+		event, err := c.Command.Parse(c.Args)
+		if err != c.ExpectedErr {
+			t.Errorf("incorrect error: case %d, got: %v, want: %v", idx, err, c.ExpectedErr)
+		}
+		if !reflect.DeepEqual(event, c.ExpectedEvent) {
+			t.Errorf("incorrect event: case %d, got: %+v, want: %+v", idx, event, c.ExpectedEvent)
+		}
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	c := types.Command{
+		Name: "NEW",
+		Args: []types.Arg{
+			{Name: "room"},
+			{Name: "type", Optional: true},
+			{Name: "key=value", Variadic: true},
+		},
+	}
+	if usage := c.Usage(); usage != "NEW <room> [type] [key=value...]" {
+		t.Errorf("incorrect usage: got: '%s'", usage)
+	}
+}
@@ -0,0 +1,93 @@
+package types
+
+import "fmt"
+
+// Protocol error codes. These codes are sent to client with `ERR` event, so
+// client can react to errors without parsing error message.
+const (
+	ErrCodeUnknownCommand = "UNKNOWN_COMMAND" // command is not known
+	ErrCodeWrongArity     = "WRONG_ARITY"     // wrong number of arguments
+	ErrCodeNotANumber     = "NOT_A_NUMBER"    // coordinate is not a number
+	ErrCodeOutOfRange     = "OUT_OF_RANGE"    // coordinate is out of map
+	ErrCodeNoSuchRoom     = "NO_SUCH_ROOM"    // room does not exist
+	ErrCodeBadName        = "BAD_NAME"        // player name is not valid
+	ErrCodeNameTaken      = "NAME_TAKEN"      // player name is used
+	ErrCodeLineTooLong    = "LINE_TOO_LONG"   // input line is too long
+	ErrCodeBadToken       = "BAD_TOKEN"       // resume token is not valid
+	ErrCodeSpectator      = "SPECTATOR"       // spectators cannot play
+	ErrCodeNoSuchPlayer   = "NO_SUCH_PLAYER"  // player is not online
+	ErrCodeRateLimited    = "RATE_LIMITED"    // too many messages
+	ErrCodeZombie         = "ZOMBIE"          // zombies cannot shoot
+	ErrCodeCooldown       = "COOLDOWN"        // zombie moves too fast
+	ErrCodeWrongContext   = "WRONG_CONTEXT"   // command cannot be used here
+)
+
+var (
+	// ErrUnknownCommand will be returned when command cannot be identified.
+	ErrUnknownCommand = &ProtocolError{ErrCodeUnknownCommand, "unknown command"}
+
+	// ErrWrongArity will be returned when command has wrong number of
+	// arguments.
+	ErrWrongArity = &ProtocolError{ErrCodeWrongArity, "wrong number of arguments"}
+
+	// ErrNotANumber will be returned when coordinate is not a number.
+	ErrNotANumber = &ProtocolError{ErrCodeNotANumber, "coordinates must be numbers"}
+
+	// ErrOutOfRange will be returned when coordinate is outside of the map.
+	ErrOutOfRange = &ProtocolError{ErrCodeOutOfRange, "coordinates are out of range"}
+
+	// ErrBadName will be returned when player name is not valid.
+	ErrBadName = &ProtocolError{ErrCodeBadName, fmt.Sprintf("name must be 1-%d letters, digits, '-' or '_'", MaxNameLength)}
+
+	// ErrLineTooLong will be returned when client sends line that is longer
+	// than allowed. Such line is discarded.
+	ErrLineTooLong = &ProtocolError{ErrCodeLineTooLong, "line is too long"}
+
+	// ErrBadToken will be returned when client tries to resume session
+	// that does not exist or is not waiting for resume anymore.
+	ErrBadToken = &ProtocolError{ErrCodeBadToken, "resume token is not valid or expired"}
+
+	// ErrSpectator will be returned when spectator tries to affect the
+	// game e.g. with `SHOOT` command.
+	ErrSpectator = &ProtocolError{ErrCodeSpectator, "spectators cannot play, LEAVE and START to join the game"}
+
+	// ErrRateLimited will be returned when player sends chat messages too
+	// fast.
+	ErrRateLimited = &ProtocolError{ErrCodeRateLimited, "you are sending messages too fast"}
+
+	// ErrZombie will be returned when player that plays as zombie tries
+	// to use commands of archers e.g. `SHOOT`.
+	ErrZombie = &ProtocolError{ErrCodeZombie, "zombies cannot shoot, use MOVE <dx> <dy>"}
+
+	// ErrCooldown will be returned when zombie is moved again before move
+	// cooldown passes.
+	ErrCooldown = &ProtocolError{ErrCodeCooldown, "zombie is too tired, wait before next move"}
+
+	// ErrWrongContext will be returned when command is known, but cannot
+	// be used in the lobby or in the room where client is now, e.g.
+	// `SHOOT` in the lobby.
+	ErrWrongContext = &ProtocolError{ErrCodeWrongContext, "command cannot be used here, type HELP to see available commands"}
+)
+
+// ProtocolError is error that should be reported back to client. Code is one
+// of ErrCode* constants and Msg is human readable explanation.
+type ProtocolError struct {
+	Code string
+	Msg  string
+}
+
+// Error will return human readable error message.
+func (e *ProtocolError) Error() string {
+	return e.Msg
+}
+
+// Event will convert error into `ERR` event that can be sent to client e.g.:
+//
+//	ERR UNKNOWN_COMMAND unknown command
+func (e *ProtocolError) Event() Event {
+	return Event{
+		Type:  EventErr,
+		Actor: e.Code,
+		Args:  []string{e.Msg},
+	}
+}
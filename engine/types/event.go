@@ -1,6 +1,10 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // Define expected events. Events are used in rooms to describe what is
 // happening.
@@ -18,33 +22,234 @@ const (
 	// about how allow mutliroom server to join multiple clients, we need
 	// additional commands to join room or create a new one.
 	EventJoin = "JOIN" // join to given room `JOIN woods`
-	EventNew  = "NEW"  // create new room
+	EventNew  = "NEW"  // create new room `NEW world1 wall width=20`
+
+	// EventRematch is used to vote for another round when game is over.
+	// `RESTART` is accepted as an alias.
+	EventRematch = "REMATCH"
+
+	// EventLeave is used to leave the room and return to the lobby.
+	EventLeave = "LEAVE"
+
+	// zombie leader events. Boss announces himself and his phase changes
+	// to players with `BOSS boss-bob rage 7/12`. Zombies can ask room to
+	// spawn minions `SPAWN boss-bob crawler 12 3`, room does not pass
+	// SPAWN events to players.
+	EventBoss  = "BOSS"
+	EventSpawn = "SPAWN"
+
+	// lobby commands. These commands show information about rooms and
+	// are handled by server while client is in the lobby.
+	EventList = "LIST" // list rooms `LIST`
+	EventWho  = "WHO"  // list players in the room `WHO woods`
+	EventInfo = "INFO" // show rules of the room `INFO woods`
+
+	// EventWatch is used to watch the room as spectator `WATCH woods`.
+	// Spectators receive room events, but cannot play.
+	EventWatch = "WATCH"
+
+	// EventMove is used by player that joined the room as zombie with
+	// `JOIN woods AS ZOMBIE` to move his zombie `MOVE -1 0`.
+	EventMove = "MOVE"
+
+	// EventScore is used to show scoreboard of the room. Room sends
+	// SCORE event to players when team score changes
+	// `SCORE players 2 5`.
+	EventScore = "SCORE"
+
+	// room state events. These events are sent to players, so automated
+	// clients can follow what is happening in the room.
+	EventGameOver = "GAMEOVER" // game is over `GAMEOVER zombies wall breached`
+	EventJoined   = "JOINED"   // player joined the room `JOINED vanagas`
+	EventLeft     = "LEFT"     // player left the room `LEFT vanagas`
+
+	// keepalive commands. Client can send `PING [token]` at any time and
+	// server responds with `PONG [token]`, so client can measure latency
+	// and keep idle session alive.
+	EventPing = "PING"
+	EventPong = "PONG"
+
+	// chat commands. `SAY <text>` is sent to everyone in the room,
+	// `TELL <name> <text>` to one player and `SHOUT <text>` to everyone
+	// in the lobby. Messages are delivered as CHAT event with sender as
+	// Actor, channel and text as Args `CHAT vanagas room hello`.
+	EventSay   = "SAY"
+	EventTell  = "TELL"
+	EventShout = "SHOUT"
+	EventChat  = "CHAT"
+
+	// session resume. Server sends `TOKEN <token>` when player starts
+	// the game. If connection drops, client can reconnect and send
+	// `RESUME <token>` from the lobby to return to the same room.
+	EventToken  = "TOKEN"
+	EventResume = "RESUME"
+
+	// EventHelp is used to list commands that can be used in the lobby
+	// or in the room.
+	EventHelp = "HELP"
+
+	// EventErr is sent to client when command cannot be handled. Error
+	// code is stored as Actor and error message as Args.
+	EventErr = "ERR"
+)
+
+// Chat channels used in CHAT event.
+const (
+	ChatRoom  = "room"  // message to everyone in the room
+	ChatTell  = "tell"  // private message
+	ChatLobby = "lobby" // message to everyone in the lobby
 )
 
 // Event will be used for various events in this engine. For example if player
 // tries to shoot zombie, when zombie dies or walks. All events should be
 // processed into room. Each room implemeation can interpretate events
-// differently.
+// differently. Args holds additional command arguments e.g. room type and
+// options for `NEW` command. Max holds points team needs to win in SCORE
+// event. HP and MaxHP holds health of zombie in WALK event, they are left
+// zero if zombie does not report health.
 type Event struct {
-	Type   string
-	Actor  string
-	X, Y   int64
-	Points int
-	Hits   []string
+	Type      string
+	Actor     string
+	X, Y      int64
+	Points    int
+	Max       int
+	HP, MaxHP int
+	Hits      []string
+	Args      []string
 }
 
 // String will convert event into human readable string. E.g.:
 //
 //	WALK zombie 1 7
-//
+//	WALK armored-zombie 1 7 2/3
 func (e *Event) String() (s string) {
 	switch e.Type {
 	case EventWalk:
 		s = fmt.Sprintf("%s %s %d %d", e.Type, e.Actor, e.X, e.Y)
+		if e.MaxHP > 0 {
+			s += fmt.Sprintf(" %d/%d", e.HP, e.MaxHP)
+		}
 	case EventShoot:
 		s = fmt.Sprintf("%s %d %d", e.Type, e.X, e.Y)
 	case EventBoom:
 		s = fmt.Sprintf("%s %s %d %v", e.Type, e.Actor, e.Points, e.Hits)
+	case EventErr, EventGameOver:
+		s = fmt.Sprintf("%s %s %s", e.Type, e.Actor, strings.Join(e.Args, " "))
+	case EventScore:
+		s = fmt.Sprintf("%s %s %d %d", e.Type, e.Actor, e.Points, e.Max)
+	case EventJoined, EventLeft, EventToken:
+		s = fmt.Sprintf("%s %s", e.Type, e.Actor)
+	case EventPing, EventPong:
+		s = strings.Join(append([]string{e.Type}, e.Args...), " ")
+	case EventChat:
+		s = fmt.Sprintf("%s %s %s", e.Type, e.Actor, strings.Join(e.Args, " "))
+	case EventBoss:
+		s = fmt.Sprintf("%s %s %s %d/%d", e.Type, e.Actor, strings.Join(e.Args, " "), e.HP, e.MaxHP)
+	case EventSpawn:
+		s = fmt.Sprintf("%s %s %s %d %d", e.Type, e.Actor, strings.Join(e.Args, " "), e.X, e.Y)
+	}
+	return
+}
+
+// ParseEvent will parse event line sent by server. This is opposite of
+// Event.String and can be used by automated clients e.g.:
+//
+//	GAMEOVER players 5 zombies killed
+func ParseEvent(line string) (Event, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Event{}, ErrUnknownCommand
+	}
+
+	e := Event{Type: fields[0]}
+	var err error
+	switch {
+	case e.Type == EventWalk && (len(fields) == 4 || len(fields) == 5):
+		e.Actor = fields[1]
+		e.X, e.Y, err = parseXY(fields[2], fields[3])
+		if err == nil && len(fields) == 5 {
+			e.HP, e.MaxHP, err = parseHP(fields[4])
+		}
+	case e.Type == EventShoot && len(fields) == 3:
+		e.X, e.Y, err = parseXY(fields[1], fields[2])
+	case e.Type == EventBoom && len(fields) >= 4:
+		e.Actor = fields[1]
+		e.Points, err = strconv.Atoi(fields[2])
+		hits := strings.Join(fields[3:], " ")
+		e.Hits = strings.Fields(strings.Trim(hits, "[]"))
+	case (e.Type == EventErr || e.Type == EventGameOver) && len(fields) >= 2:
+		e.Actor = fields[1]
+		e.Args = []string{strings.Join(fields[2:], " ")}
+	case e.Type == EventScore && len(fields) == 4:
+		e.Actor = fields[1]
+		if e.Points, err = strconv.Atoi(fields[2]); err == nil {
+			e.Max, err = strconv.Atoi(fields[3])
+		}
+	case (e.Type == EventJoined || e.Type == EventLeft || e.Type == EventToken) && len(fields) == 2:
+		e.Actor = fields[1]
+	case e.Type == EventChat && len(fields) >= 4:
+		e.Actor = fields[1]
+		e.Args = []string{fields[2], strings.Join(fields[3:], " ")}
+	case e.Type == EventBoss && len(fields) == 4:
+		e.Actor = fields[1]
+		e.Args = []string{fields[2]}
+		e.HP, e.MaxHP, err = parseHP(fields[3])
+	case e.Type == EventPing || e.Type == EventPong:
+		if len(fields) > 1 {
+			e.Args = fields[1:]
+		}
+	case e.Type == EventWalk, e.Type == EventShoot, e.Type == EventBoom,
+		e.Type == EventErr, e.Type == EventGameOver, e.Type == EventScore,
+		e.Type == EventJoined, e.Type == EventLeft, e.Type == EventToken,
+		e.Type == EventChat, e.Type == EventBoss:
+		return Event{}, ErrWrongArity
+	default:
+		return Event{}, ErrUnknownCommand
+	}
+	if err != nil {
+		return Event{}, ErrNotANumber
+	}
+	return e, nil
+}
+
+// FormatHit will describe zombie hit by arrow in BOOM event together with its
+// remaining health e.g. `armored-zombie:2/3`. Health 0 means zombie was killed.
+func FormatHit(name string, hp, maxHP int) string {
+	return fmt.Sprintf("%s:%d/%d", name, hp, maxHP)
+}
+
+// ParseHit is opposite of FormatHit. If hit does not report health, name is
+// returned as is and maxHP is 0.
+func ParseHit(hit string) (name string, hp, maxHP int) {
+	i := strings.LastIndex(hit, ":")
+	if i < 0 {
+		return hit, 0, 0
+	}
+	hp, maxHP, err := parseHP(hit[i+1:])
+	if err != nil {
+		return hit, 0, 0
+	}
+	return hit[:i], hp, maxHP
+}
+
+// parseHP will parse health in `cur/max` form.
+func parseHP(s string) (hp, maxHP int, err error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return 0, 0, ErrNotANumber
+	}
+	if hp, err = strconv.Atoi(parts[0]); err != nil {
+		return
+	}
+	maxHP, err = strconv.Atoi(parts[1])
+	return
+}
+
+// parseXY will parse coordinates.
+func parseXY(xs, ys string) (x, y int64, err error) {
+	if x, err = strconv.ParseInt(xs, 10, 64); err != nil {
+		return
 	}
+	y, err = strconv.ParseInt(ys, 10, 64)
 	return
 }
@@ -1,6 +1,8 @@
 package types_test
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/sheirys/zombebattle/engine/types"
@@ -20,6 +22,37 @@ func TestEventToString(t *testing.T) {
 			},
 			ExpectedString: "WALK zombie 1 2",
 		},
+		{
+			Event: types.Event{
+				Type:  types.EventWalk,
+				Actor: "armored",
+				X:     1,
+				Y:     2,
+				HP:    2,
+				MaxHP: 3,
+			},
+			ExpectedString: "WALK armored 1 2 2/3",
+		},
+		{
+			Event: types.Event{
+				Type:  types.EventBoss,
+				Actor: "boss",
+				Args:  []string{"rage"},
+				HP:    7,
+				MaxHP: 12,
+			},
+			ExpectedString: "BOSS boss rage 7/12",
+		},
+		{
+			Event: types.Event{
+				Type:  types.EventSpawn,
+				Actor: "boss",
+				Args:  []string{"crawler"},
+				X:     12,
+				Y:     3,
+			},
+			ExpectedString: "SPAWN boss crawler 12 3",
+		},
 		{
 			Event: types.Event{
 				Type:  types.EventShoot,
@@ -38,6 +71,62 @@ func TestEventToString(t *testing.T) {
 			},
 			ExpectedString: "BOOM player 1 []",
 		},
+		{
+			Event:          types.ErrUnknownCommand.Event(),
+			ExpectedString: "ERR UNKNOWN_COMMAND unknown command",
+		},
+		{
+			Event: types.Event{
+				Type:  types.EventGameOver,
+				Actor: "players",
+				Args:  []string{"5 zombies killed"},
+			},
+			ExpectedString: "GAMEOVER players 5 zombies killed",
+		},
+		{
+			Event: types.Event{
+				Type:   types.EventScore,
+				Actor:  "zombies",
+				Points: 2,
+				Max:    5,
+			},
+			ExpectedString: "SCORE zombies 2 5",
+		},
+		{
+			Event: types.Event{
+				Type:  types.EventJoined,
+				Actor: "vanagas",
+			},
+			ExpectedString: "JOINED vanagas",
+		},
+		{
+			Event: types.Event{
+				Type:  types.EventLeft,
+				Actor: "vanagas",
+			},
+			ExpectedString: "LEFT vanagas",
+		},
+		{
+			Event: types.Event{
+				Type:  types.EventChat,
+				Actor: "vanagas",
+				Args:  []string{types.ChatTell, "behind you"},
+			},
+			ExpectedString: "CHAT vanagas tell behind you",
+		},
+		{
+			Event: types.Event{
+				Type: types.EventPong,
+				Args: []string{"42"},
+			},
+			ExpectedString: "PONG 42",
+		},
+		{
+			Event: types.Event{
+				Type: types.EventPong,
+			},
+			ExpectedString: "PONG",
+		},
 	}
 
 	for idx, c := range testTable {
@@ -47,3 +136,127 @@ func TestEventToString(t *testing.T) {
 		}
 	}
 }
+
+func TestParseEvent(t *testing.T) {
+	testTable := []struct {
+		Line          string
+		ExpectedEvent types.Event
+		ExpectedErr   error
+	}{
+		{
+			Line:          "WALK zombie1 10 3",
+			ExpectedEvent: types.Event{Type: types.EventWalk, Actor: "zombie1", X: 10, Y: 3},
+		},
+		{
+			Line:          "WALK armored1 10 3 1/3",
+			ExpectedEvent: types.Event{Type: types.EventWalk, Actor: "armored1", X: 10, Y: 3, HP: 1, MaxHP: 3},
+		},
+		{
+			Line:          "BOOM vanagas 1 [armored1:0/3 zombie2]",
+			ExpectedEvent: types.Event{Type: types.EventBoom, Actor: "vanagas", Points: 1, Hits: []string{"armored1:0/3", "zombie2"}},
+		},
+		{
+			Line:          "BOOM vanagas 2 [zombie1 zombie2]",
+			ExpectedEvent: types.Event{Type: types.EventBoom, Actor: "vanagas", Points: 2, Hits: []string{"zombie1", "zombie2"}},
+		},
+		{
+			Line:          "BOOM vanagas 0 []\n",
+			ExpectedEvent: types.Event{Type: types.EventBoom, Actor: "vanagas", Points: 0, Hits: []string{}},
+		},
+		{
+			Line:          "GAMEOVER zombies wall breached 5 times",
+			ExpectedEvent: types.Event{Type: types.EventGameOver, Actor: "zombies", Args: []string{"wall breached 5 times"}},
+		},
+		{
+			Line:          "SCORE players 1 5",
+			ExpectedEvent: types.Event{Type: types.EventScore, Actor: "players", Points: 1, Max: 5},
+		},
+		{
+			Line:          "JOINED vanagas",
+			ExpectedEvent: types.Event{Type: types.EventJoined, Actor: "vanagas"},
+		},
+		{
+			Line:          "LEFT vanagas",
+			ExpectedEvent: types.Event{Type: types.EventLeft, Actor: "vanagas"},
+		},
+		{
+			Line:          "CHAT vanagas room behind you",
+			ExpectedEvent: types.Event{Type: types.EventChat, Actor: "vanagas", Args: []string{"room", "behind you"}},
+		},
+		{
+			Line:          "TOKEN 0a1b2c",
+			ExpectedEvent: types.Event{Type: types.EventToken, Actor: "0a1b2c"},
+		},
+		{
+			Line:          "PONG 42",
+			ExpectedEvent: types.Event{Type: types.EventPong, Args: []string{"42"}},
+		},
+		{
+			Line:          "PONG",
+			ExpectedEvent: types.Event{Type: types.EventPong},
+		},
+		{
+			Line:          "ERR NO_SUCH_ROOM room 'woods' does not exist",
+			ExpectedEvent: types.Event{Type: types.EventErr, Actor: "NO_SUCH_ROOM", Args: []string{"room 'woods' does not exist"}},
+		},
+		{
+			Line:        "SCORE players one 5",
+			ExpectedErr: types.ErrNotANumber,
+		},
+		{
+			Line:          "BOSS boss1 frenzy 2/12",
+			ExpectedEvent: types.Event{Type: types.EventBoss, Actor: "boss1", Args: []string{"frenzy"}, HP: 2, MaxHP: 12},
+		},
+		{
+			Line:        "BOSS boss1 frenzy",
+			ExpectedErr: types.ErrWrongArity,
+		},
+		{
+			Line:        "WALK armored1 10 3 full",
+			ExpectedErr: types.ErrNotANumber,
+		},
+		{
+			Line:        "JOINED",
+			ExpectedErr: types.ErrWrongArity,
+		},
+		{
+			Line:        "# players win",
+			ExpectedErr: types.ErrUnknownCommand,
+		},
+	}
+
+	for idx, c := range testTable {
+		event, err := types.ParseEvent(c.Line)
+		if err != c.ExpectedErr {
+			t.Errorf("incorrect error: case %d, got: %v, want: %v", idx, err, c.ExpectedErr)
+		}
+		if !reflect.DeepEqual(event, c.ExpectedEvent) {
+			t.Errorf("incorrect event: case %d, got: %+v, want: %+v", idx, event, c.ExpectedEvent)
+		}
+		// event should be formatted back to the same line.
+		if err == nil && event.String() != strings.TrimSpace(c.Line) {
+			t.Errorf("incorrect event format: case %d, got: '%s', want: '%s'", idx, event.String(), c.Line)
+		}
+	}
+}
+
+func TestParseHit(t *testing.T) {
+	testTable := []struct {
+		Hit           string
+		ExpectedName  string
+		ExpectedHP    int
+		ExpectedMaxHP int
+	}{
+		{Hit: "zombie1", ExpectedName: "zombie1"},
+		{Hit: types.FormatHit("armored1", 2, 3), ExpectedName: "armored1", ExpectedHP: 2, ExpectedMaxHP: 3},
+		{Hit: "armored1:0/3", ExpectedName: "armored1", ExpectedHP: 0, ExpectedMaxHP: 3},
+		{Hit: "guest:x", ExpectedName: "guest:x"},
+	}
+
+	for idx, c := range testTable {
+		name, hp, maxHP := types.ParseHit(c.Hit)
+		if name != c.ExpectedName || hp != c.ExpectedHP || maxHP != c.ExpectedMaxHP {
+			t.Errorf("incorrect hit: case %d, got: %s %d/%d, want: %s %d/%d", idx, name, hp, maxHP, c.ExpectedName, c.ExpectedHP, c.ExpectedMaxHP)
+		}
+	}
+}
@@ -1,9 +1,50 @@
 package types
 
+import "fmt"
+
+// MaxNameLength defines how long player name can be.
+const MaxNameLength = 16
+
+// Player defines what we expect from player. ID is unique session identifier
+// given by server for each connection and Name is name chosen by player with
+// `START <name>` command. Rooms should use ID or player itself to attribute
+// events to the player and Name to display it. Notify and ProcessEvent should
+// not block, because rooms call them from their event loop. EventStream should
+// return events produced by player in the room. Room should get it once, when
+// player is added, and read it until it is closed, so events meant for other
+// room are never read.
 type Player interface {
+	ID() string
+	Name() string
 	Notify(msg string)
-	GetEvent() (Event, bool)
+	EventStream() <-chan Event
 	ProcessEvent(e Event)
 	ProduceEvent(e Event)
 	Drop()
 }
+
+// ValidateName will return ErrBadName if name cannot be used as player name.
+// Name can hold only letters, digits, '-' and '_' and cannot be longer than
+// MaxNameLength.
+func ValidateName(name string) error {
+	if len(name) == 0 || len(name) > MaxNameLength {
+		return ErrBadName
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return ErrBadName
+		}
+	}
+	return nil
+}
+
+// ErrNameTaken will return protocol error for name that is already used by
+// other player.
+func ErrNameTaken(name string) error {
+	return &ProtocolError{
+		Code: ErrCodeNameTaken,
+		Msg:  fmt.Sprintf("name '%s' is already taken", name),
+	}
+}
@@ -0,0 +1,28 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+func TestValidateName(t *testing.T) {
+	testTable := []struct {
+		Name        string
+		ExpectedErr error
+	}{
+		{"vanagas", nil},
+		{"Bob_the-2nd", nil},
+		{"abcdefghijklmnop", nil},
+		{"abcdefghijklmnopq", types.ErrBadName},
+		{"", types.ErrBadName},
+		{"bob!", types.ErrBadName},
+		{"žemaitis", types.ErrBadName},
+	}
+
+	for idx, c := range testTable {
+		if err := types.ValidateName(c.Name); err != c.ExpectedErr {
+			t.Errorf("incorrect error: case %d, got: %v, want: %v", idx, err, c.ExpectedErr)
+		}
+	}
+}
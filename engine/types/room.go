@@ -1,5 +1,10 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Room defines what we expect from room. Please notice that we do not provide
 // map size here. Map size is not a requirement and if you want you can
 // implement unlimited size map. Also how scores are calculated depends on
@@ -34,9 +39,140 @@ type Room interface {
 	// AddPlayer should attach client to this room.
 	AddPlayer(p Player) error
 
+	// RemovePlayer should detach client from this room. Room should ignore
+	// events from this player, but keep reading his event stream until it
+	// is closed.
+	RemovePlayer(p Player) error
+
 	// ZombiesWon should return true if zombies won this room.
 	ZombiesWon() bool
 
 	// PlayersWon should return true if players won this room.
 	PlayersWon() bool
+
+	// State should return current state of this room.
+	State() RoomState
+
+	// Players should return players that are currently in this room.
+	// Players that are disconnected should not be returned.
+	Players() []Player
+
+	// ZombieCount should return how many zombies are living in this room.
+	ZombieCount() int
+}
+
+// Scorer can be implemented by room that keeps scores. Scores are shown in
+// the lobby with `LIST` command.
+type Scorer interface {
+	Scores() []Score
+}
+
+// Score describes points collected by one team in the room. Max is points
+// team needs to win. Zero Max means that there is no limit.
+type Score struct {
+	Team   string
+	Points int64
+	Max    int64
+}
+
+// String will return human readable score e.g. `players 2/5`.
+func (s Score) String() string {
+	if s.Max == 0 {
+		return fmt.Sprintf("%s %d", s.Team, s.Points)
+	}
+	return fmt.Sprintf("%s %d/%d", s.Team, s.Points, s.Max)
+}
+
+// Describer can be implemented by room that wants to explain its rules to
+// clients. Rules are shown in the lobby with `INFO <room>` command.
+type Describer interface {
+	Rules() string
+}
+
+// Snapshotter can be implemented by room that wants to replay its current
+// state, e.g. zombie positions and scores, to player that resumes session
+// after disconnect.
+type Snapshotter interface {
+	Snapshot() []Event
+}
+
+// Watchable can be implemented by room that can be watched by spectators.
+// Spectators should receive the same events as players, but they cannot affect
+// the game and should not be returned by Room.Players.
+type Watchable interface {
+	AddSpectator(p Player) error
+	RemoveSpectator(p Player) error
+	Spectators() []Player
+}
+
+// ZombieRemover can be implemented by room that can remove zombie while game
+// is running. Rooms that implement it and types.Watchable can be joined by
+// players as zombies with `JOIN <room> AS ZOMBIE` command.
+type ZombieRemover interface {
+	RemoveZombie(z Zombie) error
+}
+
+// Restarter can be implemented by room that can start new round after game
+// is over. Server uses it to reopen finished default room when all players
+// have left it.
+type Restarter interface {
+	Restart() error
+}
+
+// RoomState describes in which stage of the game room is.
+type RoomState int
+
+// Possible room states.
+const (
+	RoomWaiting  RoomState = iota // room is waiting for players.
+	RoomRunning                   // game is in progress.
+	RoomFinished                  // game is over or room is stopped.
+)
+
+// String will return human readable room state.
+func (s RoomState) String() string {
+	switch s {
+	case RoomWaiting:
+		return "waiting"
+	case RoomRunning:
+		return "running"
+	case RoomFinished:
+		return "finished"
+	}
+	return "unknown"
+}
+
+// RoomOptions holds room settings passed when room is created e.g. with
+// `NEW <name> <type> [key=value...]` command. Option keys are lower-cased.
+type RoomOptions map[string]string
+
+// OptionError should be returned by RoomFactory when room option is not valid,
+// so configuration can point to the offending option. Key is lower-cased
+// option key.
+type OptionError struct {
+	Key string
+	Msg string
+}
+
+// Error will return human readable error e.g.
+// `bad room option 'kills': must be at least 1`.
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("bad room option '%s': %s", e.Key, e.Msg)
+}
+
+// RoomFactory should create new room of registered type with given options.
+// Error should be returned if options are not valid for this room type.
+type RoomFactory func(opts RoomOptions) (Room, error)
+
+// ParseRoomOptions will parse `key=value` arguments into RoomOptions.
+func ParseRoomOptions(args []string) (RoomOptions, error) {
+	opts := RoomOptions{}
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("bad room option '%s', expected key=value", arg)
+		}
+		opts[strings.ToLower(kv[0])] = kv[1]
+	}
+	return opts, nil
 }
@@ -0,0 +1,53 @@
+package types_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+func TestParseRoomOptions(t *testing.T) {
+	testTable := []struct {
+		Args            []string
+		ExpectedOptions types.RoomOptions
+		ExpectErr       bool
+	}{
+		{
+			Args:            []string{},
+			ExpectedOptions: types.RoomOptions{},
+		},
+		{
+			Args:            []string{"WIDTH=20", "height=5"},
+			ExpectedOptions: types.RoomOptions{"width": "20", "height": "5"},
+		},
+		{
+			Args:            []string{"name=a=b"},
+			ExpectedOptions: types.RoomOptions{"name": "a=b"},
+		},
+		{
+			Args:      []string{"width"},
+			ExpectErr: true,
+		},
+		{
+			Args:      []string{"=20"},
+			ExpectErr: true,
+		},
+	}
+
+	for idx, c := range testTable {
+		opts, err := types.ParseRoomOptions(c.Args)
+		if c.ExpectErr {
+			if err == nil {
+				t.Errorf("expected error: case %d", idx)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: case %d, got: %s", idx, err)
+		}
+		if !reflect.DeepEqual(opts, c.ExpectedOptions) {
+			t.Errorf("wrong options: case %d, got: %v, want: %v", idx, opts, c.ExpectedOptions)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/sheirys/zombebattle/engine/players"
 	"github.com/sheirys/zombebattle/engine/rooms"
 	"github.com/sheirys/zombebattle/engine/types"
 )
@@ -13,12 +14,14 @@ import (
 // with same arguments.
 func TestRoomInterface(t *testing.T) {
 	testWithInterface(t, &rooms.TrainingGrounds{})
+	testWithInterface(t, &rooms.TheWall{})
 }
 
 func testWithInterface(t *testing.T, i types.Room) {
 	// functions that should be tested with this interface implementation.
 	testFunc := []func(*testing.T, types.Room){
 		testName,
+		testRemovePlayer,
 	}
 
 	// call all testable functions.
@@ -43,3 +46,28 @@ func testName(t *testing.T, i types.Room) {
 		}
 	}
 }
+
+func testRemovePlayer(t *testing.T, i types.Room) {
+	player := &players.MockPlayer{
+		Events: make(chan types.Event),
+	}
+
+	i.Init()
+	defer i.Stop()
+
+	i.AddPlayer(player)
+	if count := len(i.Players()); count != 1 {
+		t.Errorf("wrong player count: got: %d, want: 1", count)
+	}
+
+	if err := i.RemovePlayer(player); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if count := len(i.Players()); count != 0 {
+		t.Errorf("wrong player count after remove: got: %d, want: 0", count)
+	}
+
+	if err := i.RemovePlayer(player); err == nil {
+		t.Errorf("expected error when removing player that is not in the room")
+	}
+}
@@ -1,15 +1,26 @@
 package types
 
 // ServerRoom defines rooms holded by server. Room should be pointer to room and
-// default defines if this room is default room in server.
+// default defines if this room is default room in server. Type is registered
+// room type name and can be empty for rooms that are predefined in server.
+// Zombies will be added into room when server starts it.
 type ServerRoom struct {
 	Room    Room
 	Default bool
+	Type    string
+	Zombies []Zombie
 }
 
 // Lobby defines what rooms are registered in server. This struct is returned to
 // client, when we want to inform him, what rooms are available at this moment.
+// Scores and Rules are empty if room does not implement Scorer or Describer.
 type Lobby struct {
 	Name    string
 	Default bool
+	Type    string
+	State   RoomState
+	Players int
+	Zombies int
+	Scores  []Score
+	Rules   string
 }
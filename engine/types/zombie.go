@@ -4,15 +4,17 @@ import "context"
 
 // Zombie defines what we expect from zombie and how we are going to control him.
 // We will have different type of zombies;- a dumb zombie which will move only
-// in x axis (zombie.Easy), a rabbit zombie which will jump in random coordinates
-// (we call it zombie.Hard). This interface allows us to implement different
+// in x axis (zombies.Crawler), a rabbit zombie which will jump in random
+// coordinates (zombies.Rabbit). This interface allows us to implement different
 // kind of zombies like twitter zombie which will move by some random tweets or
-// telnet zombie which can be controlled by network, because you know, why not?
+// telnet zombie which can be controlled by network (zombies.Remote), because
+// you know, why not?
 type Zombie interface {
 
 	// Summon will spanw a zombie and all his movements/events will be sent
-	// to e chan. Context can be used to stop zombies.
-	Summon(ctx context.Context, moves chan Event) error
+	// to e chan. Context can be used to stop zombies. Bounds describe map
+	// of the room, zombie should not leave it.
+	Summon(ctx context.Context, moves chan Event, bounds Bounds) error
 
 	Run()
 
@@ -38,3 +40,22 @@ type Zombie interface {
 	// Next should force zombie to move.
 	Next()
 }
+
+// Bounds describes map of the room where zombie lives. MaxX and MaxY are the
+// largest coordinates on the map, the smallest ones are 0.
+type Bounds struct {
+	MaxX, MaxY int64
+}
+
+// Contains will return true if given coordinates are inside bounds.
+func (b Bounds) Contains(x, y int64) bool {
+	return x >= 0 && y >= 0 && x <= b.MaxX && y <= b.MaxY
+}
+
+// HealthReporter is optional interface for zombies that have more than 1 HP.
+// Rooms use it to tell players how much health zombie has left after hit, so
+// players know that arrow landed but did not kill.
+type HealthReporter interface {
+	// HP will return current and max health of zombie.
+	HP() (cur, max int)
+}
@@ -0,0 +1,151 @@
+package zombies
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+// DefaultArmoredHP defines how many hits armored zombie can take if MaxHP is
+// not set.
+const DefaultArmoredHP = 3
+
+// Armored is a crawler in rusty armor. He crawls to the wall the same way as
+// Crawler does, but needs MaxHP hits before he dies. Armored reports his
+// health with HP, so rooms can tell players that arrow landed but did not
+// kill. Tick defines how often armored zombie moves.
+type Armored struct {
+	Tick  time.Duration
+	MaxHP int
+
+	name       string
+	x, y       int64
+	hp         int64
+	events     chan types.Event
+	timeToMove *time.Ticker
+	ctx        context.Context
+	kill       context.CancelFunc
+	done       chan struct{}
+}
+
+// Summon is used to initialize zombie and attach world events to it. Also
+// context must be passed here to control how long this zombie should exist.
+// Armored zombie is summoned with full health and does not need room bounds.
+func (z *Armored) Summon(ctx context.Context, e chan types.Event, bounds types.Bounds) error {
+	z.name = "armored-" + PickName()
+	z.events = e
+	z.ctx, z.kill = context.WithCancel(ctx)
+	atomic.StoreInt64(&z.hp, int64(z.maxHP()))
+
+	log.Printf("zombie '%s' has been summoned!", z.name)
+	return nil
+}
+
+// Run will start this zombie.
+func (z *Armored) Run() {
+	// start living cycle.
+	z.done = make(chan struct{})
+	go z.startLiving()
+}
+
+// GetName will return zombie name.
+func (z *Armored) GetName() string {
+	return z.name
+}
+
+// Hit will be called when player hits this zombie. Each hit takes 1 HP and
+// true is returned when zombie has no HP left. Zombie that was killed but not
+// reset by room puts on new armor with next hit.
+func (z *Armored) Hit() bool {
+	log.Printf("zombie '%s' got hit", z.name)
+	atomic.CompareAndSwapInt64(&z.hp, 0, int64(z.maxHP()))
+	return atomic.AddInt64(&z.hp, -1) <= 0
+}
+
+// HP will return current and max health of this zombie.
+func (z *Armored) HP() (int, int) {
+	return int(atomic.LoadInt64(&z.hp)), z.maxHP()
+}
+
+// Kill zombie. Room should call this when we want to force-kill this zombie.
+// Kill will block until zombie stops moving.
+func (z *Armored) Kill() error {
+	if z.kill != nil {
+		z.kill()
+	}
+	if z.done != nil {
+		<-z.done
+	}
+	return nil
+}
+
+// GetPos will return current zombie position.
+func (z *Armored) GetPos() (int64, int64) {
+	return atomic.LoadInt64(&z.x), atomic.LoadInt64(&z.y)
+}
+
+// Reset will move zombie to given position and restore his armor. Rooms call
+// Reset when zombie is respawned.
+func (z *Armored) Reset(x, y int64) error {
+	atomic.StoreInt64(&z.x, x)
+	atomic.StoreInt64(&z.y, y)
+	atomic.StoreInt64(&z.hp, int64(z.maxHP()))
+	return nil
+}
+
+// Next will force to move this zombie into next location.
+func (z *Armored) Next() {
+	z.move()
+}
+
+func (z *Armored) move() {
+	move := z.nextMove()
+	log.Printf("zombie '%s' has moved '%s'", z.name, move.String())
+	select {
+	case z.events <- move:
+	case <-z.ctx.Done():
+	}
+}
+
+func (z *Armored) startLiving() {
+	defer close(z.done)
+	tick := z.Tick
+	if tick <= 0 {
+		tick = DefaultCrawlerTick
+	}
+	z.timeToMove = time.NewTicker(tick)
+	for {
+		select {
+		case <-z.timeToMove.C:
+			z.Next()
+		case <-z.ctx.Done():
+			z.timeToMove.Stop()
+			return
+		}
+	}
+}
+
+func (z *Armored) nextMove() types.Event {
+	// armored zombie crawls to the wall on X axis 0 point like crawler.
+	atomic.AddInt64(&z.x, -1)
+	hp, maxHP := z.HP()
+
+	return types.Event{
+		Type:  "WALK",
+		Actor: z.name,
+		X:     atomic.LoadInt64(&z.x),
+		Y:     atomic.LoadInt64(&z.y),
+		HP:    hp,
+		MaxHP: maxHP,
+	}
+}
+
+func (z *Armored) maxHP() int {
+	if z.MaxHP <= 0 {
+		return DefaultArmoredHP
+	}
+	return z.MaxHP
+}
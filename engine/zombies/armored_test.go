@@ -0,0 +1,44 @@
+package zombies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+func TestArmored(t *testing.T) {
+	events := make(chan types.Event, 1)
+
+	armored := &zombies.Armored{MaxHP: 2}
+	armored.Summon(context.Background(), events, types.Bounds{MaxX: 29, MaxY: 9})
+	armored.Reset(10, 3)
+
+	if hp, maxHP := armored.HP(); hp != 2 || maxHP != 2 {
+		t.Errorf("unexpected health. got: %d/%d, want: 2/2", hp, maxHP)
+	}
+
+	armored.Next()
+	event := <-events
+	if event.X != 9 || event.Y != 3 || event.HP != 2 || event.MaxHP != 2 {
+		t.Errorf("unexpected move. got: '%s', want: 'WALK %s 9 3 2/2'", event.String(), armored.GetName())
+	}
+
+	if armored.Hit() {
+		t.Errorf("expected that armored zombie survives first hit")
+	}
+	if hp, _ := armored.HP(); hp != 1 {
+		t.Errorf("unexpected health after hit. got: %d, want: 1", hp)
+	}
+	if !armored.Hit() {
+		t.Errorf("expected that armored zombie dies from second hit")
+	}
+
+	// room respawns killed zombie with new armor.
+	armored.Reset(29, 1)
+	if hp, _ := armored.HP(); hp != 2 {
+		t.Errorf("unexpected health after reset. got: %d, want: 2", hp)
+	}
+	armored.Kill()
+}
@@ -0,0 +1,325 @@
+package zombies
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+// Default settings of boss zombie. These are used if Boss fields are not set.
+const (
+	DefaultBossHP           = 12               // hits boss can take.
+	DefaultBossSpawnEvery   = 15 * time.Second // how often boss calls minions.
+	DefaultBossMinions      = 2                // minions called at once.
+	DefaultBossInvulnerable = 2 * time.Second  // invulnerability on new phase.
+)
+
+// Boss phases. Boss starts marching, gets enraged when he loses third of his
+// HP and goes into frenzy when only third of HP is left.
+const (
+	BossMarch  = "march"  // crawls like crawler.
+	BossRage   = "rage"   // crawls twice as fast.
+	BossFrenzy = "frenzy" // crawls twice as fast and jumps between rows.
+)
+
+// Boss is zombie leader. He crawls to the wall like Crawler, but has MaxHP
+// hits and changes behaviour when he loses HP, see BossMarch, BossRage and
+// BossFrenzy phases. When phase changes, boss is invulnerable for
+// Invulnerable and announces new phase with BOSS event. Every SpawnEvery boss
+// asks room to spawn Minions crawlers next to him with SPAWN event. Tick
+// defines how often boss moves in march phase.
+type Boss struct {
+	Tick         time.Duration
+	MaxHP        int
+	SpawnEvery   time.Duration
+	Minions      int
+	Invulnerable time.Duration
+
+	name              string
+	x, y              int64
+	hp                int
+	phase             string
+	invulnerableUntil time.Time
+	bounds            types.Bounds
+	events            chan types.Event
+	changed           chan struct{}
+	changedOnce       sync.Once
+	ctx               context.Context
+	kill              context.CancelFunc
+	done              chan struct{}
+	mtx               sync.Mutex
+}
+
+// Summon is used to initialize zombie and attach world events to it. Also
+// context must be passed here to control how long this zombie should exist.
+// Boss is summoned with full health in march phase. In frenzy boss jumps only
+// between rows inside given bounds.
+func (z *Boss) Summon(ctx context.Context, e chan types.Event, bounds types.Bounds) error {
+	z.name = "boss-" + PickName()
+	z.events = e
+	z.bounds = bounds
+	z.ctx, z.kill = context.WithCancel(ctx)
+	z.mtx.Lock()
+	z.restore()
+	z.mtx.Unlock()
+
+	log.Printf("zombie '%s' has been summoned!", z.name)
+	return nil
+}
+
+// Run will start this zombie. Boss announces himself with BOSS event.
+func (z *Boss) Run() {
+	z.announce()
+
+	// start living cycle.
+	z.done = make(chan struct{})
+	go z.startLiving()
+}
+
+// GetName will return zombie name.
+func (z *Boss) GetName() string {
+	return z.name
+}
+
+// Hit will be called when player hits this zombie. Each hit takes 1 HP,
+// unless boss is invulnerable. When boss enters new phase, he becomes
+// invulnerable for a while and announces new phase. True is returned when
+// boss has no HP left.
+func (z *Boss) Hit() bool {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	if time.Now().Before(z.invulnerableUntil) {
+		log.Printf("zombie '%s' shrugged off the hit", z.name)
+		return false
+	}
+	log.Printf("zombie '%s' got hit", z.name)
+	if z.hp <= 0 {
+		// boss was killed but not reset by room.
+		z.hp = z.maxHP()
+	}
+	z.hp--
+	if z.hp <= 0 {
+		return true
+	}
+	if phase := bossPhase(z.hp, z.maxHP()); phase != z.phase {
+		z.phase = phase
+		z.invulnerableUntil = time.Now().Add(z.invulnerable())
+		z.announce()
+	}
+	return false
+}
+
+// HP will return current and max health of this zombie.
+func (z *Boss) HP() (int, int) {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	return z.hp, z.maxHP()
+}
+
+// Phase will return current phase of this boss.
+func (z *Boss) Phase() string {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	return z.phase
+}
+
+// Announcement will return BOSS event that describes current phase and health
+// of this boss e.g. `BOSS boss-bob rage 7/12`.
+func (z *Boss) Announcement() types.Event {
+	hp, maxHP := z.HP()
+	return types.Event{
+		Type:  types.EventBoss,
+		Actor: z.name,
+		Args:  []string{z.Phase()},
+		HP:    hp,
+		MaxHP: maxHP,
+	}
+}
+
+// Kill zombie. Room should call this when we want to force-kill this zombie.
+// Kill will block until zombie stops moving.
+func (z *Boss) Kill() error {
+	if z.kill != nil {
+		z.kill()
+	}
+	if z.done != nil {
+		<-z.done
+	}
+	return nil
+}
+
+// GetPos will return current zombie position.
+func (z *Boss) GetPos() (int64, int64) {
+	return atomic.LoadInt64(&z.x), atomic.LoadInt64(&z.y)
+}
+
+// Reset will move zombie to given position. Rooms call Reset when zombie is
+// respawned, so boss gets full health and starts marching again.
+func (z *Boss) Reset(x, y int64) error {
+	atomic.StoreInt64(&z.x, x)
+	atomic.StoreInt64(&z.y, y)
+	z.mtx.Lock()
+	changed := z.phase != BossMarch || z.hp != z.maxHP()
+	z.restore()
+	z.mtx.Unlock()
+	if changed {
+		z.announce()
+	}
+	return nil
+}
+
+// Next will force to move this zombie into next location.
+func (z *Boss) Next() {
+	z.send(z.nextMove())
+}
+
+// Spawn will ask room to spawn Minions crawlers next to this boss.
+func (z *Boss) Spawn() {
+	x, y := z.GetPos()
+	for i := 0; i < z.minions(); i++ {
+		z.send(types.Event{
+			Type:  types.EventSpawn,
+			Actor: z.name,
+			Args:  []string{"crawler"},
+			X:     x,
+			Y:     y,
+		})
+	}
+}
+
+func (z *Boss) send(e types.Event) {
+	log.Printf("zombie '%s' sent '%s'", z.name, e.String())
+	select {
+	case z.events <- e:
+	case <-z.ctx.Done():
+	}
+}
+
+func (z *Boss) startLiving() {
+	defer close(z.done)
+	phase := z.Phase()
+	timeToMove := time.NewTicker(z.tick(phase))
+	timeToSpawn := time.NewTicker(z.spawnEvery())
+	defer func() {
+		timeToMove.Stop()
+		timeToSpawn.Stop()
+	}()
+	changed := z.changedSignal()
+	for {
+		select {
+		case <-timeToMove.C:
+			z.Next()
+		case <-timeToSpawn.C:
+			z.Spawn()
+		case <-changed:
+			z.send(z.Announcement())
+			if p := z.Phase(); z.tick(p) != z.tick(phase) {
+				timeToMove.Stop()
+				timeToMove = time.NewTicker(z.tick(p))
+			}
+			phase = z.Phase()
+		case <-z.ctx.Done():
+			return
+		}
+	}
+}
+
+func (z *Boss) nextMove() types.Event {
+	// boss crawls to the wall on X axis 0 point like crawler. In frenzy
+	// he also jumps to random row.
+	atomic.AddInt64(&z.x, -1)
+	if z.Phase() == BossFrenzy {
+		atomic.StoreInt64(&z.y, RandomPos(0, z.bounds.MaxY+1))
+	}
+	hp, maxHP := z.HP()
+
+	return types.Event{
+		Type:  "WALK",
+		Actor: z.name,
+		X:     atomic.LoadInt64(&z.x),
+		Y:     atomic.LoadInt64(&z.y),
+		HP:    hp,
+		MaxHP: maxHP,
+	}
+}
+
+// announce will ask living cycle to send BOSS event. Hit is called by room
+// while room is processing events, so boss cannot send event from there.
+func (z *Boss) announce() {
+	select {
+	case z.changedSignal() <- struct{}{}:
+	default:
+		// announcement is already pending.
+	}
+}
+
+// changedSignal will return channel used to announce phase changes. Reset can
+// be called before Summon, so channel is created lazily.
+func (z *Boss) changedSignal() chan struct{} {
+	z.changedOnce.Do(func() {
+		z.changed = make(chan struct{}, 1)
+	})
+	return z.changed
+}
+
+// restore will give boss full health. mtx must be held.
+func (z *Boss) restore() {
+	z.hp = z.maxHP()
+	z.phase = BossMarch
+	z.invulnerableUntil = time.Time{}
+}
+
+// bossPhase will return phase of boss with given health.
+func bossPhase(hp, maxHP int) string {
+	switch {
+	case hp*3 > maxHP*2:
+		return BossMarch
+	case hp*3 > maxHP:
+		return BossRage
+	}
+	return BossFrenzy
+}
+
+// tick will return how often boss moves in given phase.
+func (z *Boss) tick(phase string) time.Duration {
+	tick := z.Tick
+	if tick <= 0 {
+		tick = DefaultCrawlerTick
+	}
+	if phase != BossMarch {
+		tick /= 2
+	}
+	return tick
+}
+
+func (z *Boss) maxHP() int {
+	if z.MaxHP <= 0 {
+		return DefaultBossHP
+	}
+	return z.MaxHP
+}
+
+func (z *Boss) spawnEvery() time.Duration {
+	if z.SpawnEvery <= 0 {
+		return DefaultBossSpawnEvery
+	}
+	return z.SpawnEvery
+}
+
+func (z *Boss) minions() int {
+	if z.Minions <= 0 {
+		return DefaultBossMinions
+	}
+	return z.Minions
+}
+
+func (z *Boss) invulnerable() time.Duration {
+	if z.Invulnerable <= 0 {
+		return DefaultBossInvulnerable
+	}
+	return z.Invulnerable
+}
@@ -0,0 +1,86 @@
+package zombies_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+func TestBossPhases(t *testing.T) {
+	events := make(chan types.Event, 10)
+
+	boss := &zombies.Boss{MaxHP: 6, Tick: time.Hour, SpawnEvery: time.Hour, Invulnerable: time.Hour}
+	boss.Summon(context.Background(), events, types.Bounds{MaxX: 29, MaxY: 9})
+	boss.Reset(10, 1)
+
+	if phase := boss.Phase(); phase != zombies.BossMarch {
+		t.Errorf("unexpected phase. got: %s, want: %s", phase, zombies.BossMarch)
+	}
+
+	boss.Hit()
+	boss.Hit()
+	if phase := boss.Phase(); phase != zombies.BossRage {
+		t.Errorf("unexpected phase. got: %s, want: %s", phase, zombies.BossRage)
+	}
+
+	// boss is invulnerable after phase change.
+	if boss.Hit() {
+		t.Errorf("expected that boss survives hit")
+	}
+	if hp, _ := boss.HP(); hp != 4 {
+		t.Errorf("invulnerable boss should not lose hp. got: %d, want: 4", hp)
+	}
+
+	// phase change is announced by living boss.
+	boss.Run()
+	expected := fmt.Sprintf("BOSS %s rage 4/6", boss.GetName())
+	if e := <-events; e.String() != expected {
+		t.Errorf("unexpected announcement. got: '%s', want: '%s'", e.String(), expected)
+	}
+
+	boss.Spawn()
+	for i := 0; i < zombies.DefaultBossMinions; i++ {
+		e := <-events
+		expected := fmt.Sprintf("SPAWN %s crawler 10 1", boss.GetName())
+		if e.String() != expected {
+			t.Errorf("unexpected spawn request. got: '%s', want: '%s'", e.String(), expected)
+		}
+	}
+	boss.Kill()
+}
+
+func TestBossKill(t *testing.T) {
+	events := make(chan types.Event, 10)
+
+	boss := &zombies.Boss{MaxHP: 6, Invulnerable: time.Nanosecond}
+	boss.Summon(context.Background(), events, types.Bounds{MaxX: 29, MaxY: 9})
+	boss.Reset(10, 1)
+
+	killed := false
+	for i := 0; i < 6; i++ {
+		time.Sleep(time.Millisecond)
+		killed = boss.Hit()
+		if i == 3 && boss.Phase() != zombies.BossFrenzy {
+			t.Errorf("unexpected phase. got: %s, want: %s", boss.Phase(), zombies.BossFrenzy)
+		}
+	}
+	if !killed {
+		t.Errorf("expected that boss dies after %d hits", 6)
+	}
+
+	// frenzied boss jumps between rows, but stays inside the map.
+	boss.Next()
+	if e := <-events; e.X != 9 || e.Y < 0 || e.Y > 9 {
+		t.Errorf("unexpected move: '%s'", e.String())
+	}
+
+	// room respawns killed boss with full health.
+	boss.Reset(29, 1)
+	if hp, maxHP := boss.HP(); hp != 6 || maxHP != 6 || boss.Phase() != zombies.BossMarch {
+		t.Errorf("unexpected boss after reset. got: %s %d/%d", boss.Phase(), hp, maxHP)
+	}
+}
@@ -9,26 +9,35 @@ import (
 	"github.com/sheirys/zombebattle/engine/types"
 )
 
+// DefaultCrawlerTick defines how often crawler moves if Tick is not set.
+const DefaultCrawlerTick = 3 * time.Second
+
 // Crawler is a zombie that shiuld be used in TheWall room. This zombie will
 // try to climb on the wall and kill archer. When zombie reaches wall it is room
-// responsibility to kill that zombie and respawn it.
+// responsibility to kill that zombie and respawn it. Tick defines how often
+// crawler moves.
 type Crawler struct {
+	Tick time.Duration
+
 	name       string
 	x, y       int64
 	events     chan types.Event
 	timeToMove *time.Ticker
 	ctx        context.Context
+	kill       context.CancelFunc
+	done       chan struct{}
 }
 
 // Summon is used to initialize zombie and attach world events to it. Also
 // context must be passed here to control how long this zombie should exist.
-func (z *Crawler) Summon(ctx context.Context, e chan types.Event) error {
+// Crawler does not need room bounds.
+func (z *Crawler) Summon(ctx context.Context, e chan types.Event, bounds types.Bounds) error {
 	// lets randomly generate name for this zombie. As we want to know what
 	// type of zombie this is, we will hardcode `crawler` in front of the
 	// name.
 	z.name = "crawler-" + PickName()
 	z.events = e
-	z.ctx = ctx
+	z.ctx, z.kill = context.WithCancel(ctx)
 
 	log.Printf("zombie '%s' has been summoned!", z.name)
 	return nil
@@ -37,6 +46,7 @@ func (z *Crawler) Summon(ctx context.Context, e chan types.Event) error {
 // Run will start this zombie.
 func (z *Crawler) Run() {
 	// start living cycle.
+	z.done = make(chan struct{})
 	go z.startLiving()
 }
 
@@ -53,8 +63,14 @@ func (z *Crawler) Hit() bool {
 }
 
 // Kill zombie. Room should call this when we want to force-kill this zombie.
+// Kill will block until zombie stops moving.
 func (z *Crawler) Kill() error {
-	z.timeToMove.Stop()
+	if z.kill != nil {
+		z.kill()
+	}
+	if z.done != nil {
+		<-z.done
+	}
 	return nil
 }
 
@@ -79,11 +95,19 @@ func (z *Crawler) Next() {
 func (z *Crawler) move() {
 	move := z.nextMove()
 	log.Printf("zombie '%s' has moved '%s'", z.name, move.String())
-	z.events <- move
+	select {
+	case z.events <- move:
+	case <-z.ctx.Done():
+	}
 }
 
 func (z *Crawler) startLiving() {
-	z.timeToMove = time.NewTicker(3 * time.Second)
+	defer close(z.done)
+	tick := z.Tick
+	if tick <= 0 {
+		tick = DefaultCrawlerTick
+	}
+	z.timeToMove = time.NewTicker(tick)
 	for {
 		select {
 		case <-z.timeToMove.C:
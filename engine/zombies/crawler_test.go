@@ -14,7 +14,7 @@ func TestCrawler(t *testing.T) {
 	event := types.Event{}
 
 	crawler := &zombies.Crawler{}
-	crawler.Summon(ctx, events)
+	crawler.Summon(ctx, events, types.Bounds{MaxX: 29, MaxY: 9})
 	crawler.Run()
 
 	crawler.Reset(5, 5)
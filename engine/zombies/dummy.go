@@ -18,17 +18,20 @@ type Dummy struct {
 	events    chan types.Event
 	heartbeat *time.Ticker
 	ctx       context.Context
+	kill      context.CancelFunc
+	done      chan struct{}
 }
 
 // Summon is used to initialize zombie and attach world events to it. Also
 // context must be passed here to control how long this zombie should exist.
-func (z *Dummy) Summon(ctx context.Context, e chan types.Event) error {
+// Dummy does not need room bounds.
+func (z *Dummy) Summon(ctx context.Context, e chan types.Event, bounds types.Bounds) error {
 	// this is dummy zombie, so whatever name we will generate lets
 	// attach "dummy-" in front of it so we will know that this zombie
 	// cannot be killed.
 	z.name = "dummy-" + PickName()
 	z.events = e
-	z.ctx = ctx
+	z.ctx, z.kill = context.WithCancel(ctx)
 
 	log.Printf("zombie '%s' has been summoned!", z.name)
 	return nil
@@ -37,6 +40,7 @@ func (z *Dummy) Summon(ctx context.Context, e chan types.Event) error {
 // Run will start this zombie.
 func (z *Dummy) Run() {
 	// start living cycle.
+	z.done = make(chan struct{})
 	go z.startLiving()
 }
 
@@ -51,10 +55,16 @@ func (z *Dummy) Hit() bool {
 	return false
 }
 
-// Kill kills zombie. Killed zombie does not move.
+// Kill kills zombie. Killed zombie does not move. Kill will block until
+// zombie stops moving.
 // FIXME: implement state e.g.: zombie.IsAlive()
 func (z *Dummy) Kill() error {
-	z.heartbeat.Stop()
+	if z.kill != nil {
+		z.kill()
+	}
+	if z.done != nil {
+		<-z.done
+	}
 	return nil
 }
 
@@ -79,10 +89,14 @@ func (z *Dummy) Next() {
 func (z *Dummy) move() {
 	move := z.nextMove()
 	log.Printf("zombie '%s' has moved '%s'", z.name, move.String())
-	z.events <- move
+	select {
+	case z.events <- move:
+	case <-z.ctx.Done():
+	}
 }
 
 func (z *Dummy) startLiving() {
+	defer close(z.done)
 	z.heartbeat = time.NewTicker(3 * time.Second)
 	for {
 		select {
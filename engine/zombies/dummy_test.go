@@ -14,7 +14,7 @@ func TestDummy(t *testing.T) {
 	event := types.Event{}
 
 	dummy := &zombies.Dummy{}
-	dummy.Summon(ctx, events)
+	dummy.Summon(ctx, events, types.Bounds{MaxX: 29, MaxY: 9})
 	dummy.Run()
 
 	dummy.Reset(5, 5)
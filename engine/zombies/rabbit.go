@@ -0,0 +1,136 @@
+package zombies
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+// DefaultRabbitTick defines how often rabbit jumps if Tick is not set.
+const DefaultRabbitTick = 2 * time.Second
+
+// Rabbit is hard zombie. Instead of crawling he jumps to random coordinates
+// inside room bounds on each tick, so archers never know where he will appear
+// next. Rabbit dies from one arrow. Tick defines how often rabbit jumps.
+type Rabbit struct {
+	Tick time.Duration
+
+	name       string
+	x, y       int64
+	bounds     types.Bounds
+	events     chan types.Event
+	timeToJump *time.Ticker
+	ctx        context.Context
+	kill       context.CancelFunc
+	done       chan struct{}
+}
+
+// Summon is used to initialize zombie and attach world events to it. Also
+// context must be passed here to control how long this zombie should exist.
+// Rabbit jumps only inside given bounds.
+func (z *Rabbit) Summon(ctx context.Context, e chan types.Event, bounds types.Bounds) error {
+	z.name = "rabbit-" + PickName()
+	z.events = e
+	z.bounds = bounds
+	z.ctx, z.kill = context.WithCancel(ctx)
+
+	log.Printf("zombie '%s' has been summoned!", z.name)
+	return nil
+}
+
+// Run will start this zombie.
+func (z *Rabbit) Run() {
+	// start living cycle.
+	z.done = make(chan struct{})
+	go z.startLiving()
+}
+
+// GetName will return zombie name.
+func (z *Rabbit) GetName() string {
+	return z.name
+}
+
+// Hit will be called when player hits this zombie. Rabbit dies from one hit.
+func (z *Rabbit) Hit() bool {
+	log.Printf("zombie '%s' got hit", z.name)
+	return true
+}
+
+// Kill zombie. Room should call this when we want to force-kill this zombie.
+// Kill will block until zombie stops jumping.
+func (z *Rabbit) Kill() error {
+	if z.kill != nil {
+		z.kill()
+	}
+	if z.done != nil {
+		<-z.done
+	}
+	return nil
+}
+
+// GetPos will return current zombie position.
+func (z *Rabbit) GetPos() (int64, int64) {
+	return atomic.LoadInt64(&z.x), atomic.LoadInt64(&z.y)
+}
+
+// Reset will move zombie to given position. But it does not respawn zombie if
+// zombie is already died.
+func (z *Rabbit) Reset(x, y int64) error {
+	atomic.StoreInt64(&z.x, x)
+	atomic.StoreInt64(&z.y, y)
+	return nil
+}
+
+// Next will force this zombie to jump into next location.
+func (z *Rabbit) Next() {
+	z.move()
+}
+
+func (z *Rabbit) move() {
+	move := z.nextMove()
+	log.Printf("zombie '%s' has moved '%s'", z.name, move.String())
+	select {
+	case z.events <- move:
+	case <-z.ctx.Done():
+	}
+}
+
+func (z *Rabbit) startLiving() {
+	defer close(z.done)
+	tick := z.Tick
+	if tick <= 0 {
+		tick = DefaultRabbitTick
+	}
+	z.timeToJump = time.NewTicker(tick)
+	for {
+		select {
+		case <-z.timeToJump.C:
+			z.Next()
+		case <-z.ctx.Done():
+			z.timeToJump.Stop()
+			return
+		}
+	}
+}
+
+func (z *Rabbit) nextMove() types.Event {
+	// jump anywhere inside the map, borders included, except x=0. Rabbit
+	// does not walk to the wall, so jumping there should not count as
+	// reaching it.
+	x := z.bounds.MaxX
+	if x >= 1 {
+		x = RandomPos(1, z.bounds.MaxX+1)
+	}
+	atomic.StoreInt64(&z.x, x)
+	atomic.StoreInt64(&z.y, RandomPos(0, z.bounds.MaxY+1))
+
+	return types.Event{
+		Type:  "WALK",
+		Actor: z.name,
+		X:     atomic.LoadInt64(&z.x),
+		Y:     atomic.LoadInt64(&z.y),
+	}
+}
@@ -0,0 +1,40 @@
+package zombies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+func TestRabbit(t *testing.T) {
+	events := make(chan types.Event, 1)
+	bounds := types.Bounds{MaxX: 3, MaxY: 2}
+
+	rabbit := &zombies.Rabbit{}
+	rabbit.Summon(context.Background(), events, bounds)
+
+	for i := 0; i < 100; i++ {
+		rabbit.Next()
+		event := <-events
+
+		if event.Type != types.EventWalk {
+			t.Fatalf("unexpected event. got: '%s', want: '%s'", event.Type, types.EventWalk)
+		}
+		if !bounds.Contains(event.X, event.Y) {
+			t.Fatalf("rabbit jumped out of bounds: %d %d", event.X, event.Y)
+		}
+		if event.X == 0 {
+			t.Fatalf("rabbit jumped to the wall: %d %d", event.X, event.Y)
+		}
+		if x, y := rabbit.GetPos(); x != event.X || y != event.Y {
+			t.Fatalf("unexpected position. got: %d %d, want: %d %d", x, y, event.X, event.Y)
+		}
+	}
+
+	if !rabbit.Hit() {
+		t.Errorf("expected that rabbit dies from one hit")
+	}
+	rabbit.Kill()
+}
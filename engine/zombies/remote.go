@@ -0,0 +1,229 @@
+package zombies
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+// DefaultRemoteCooldown defines how often remote zombie can move if Cooldown
+// is not set.
+const DefaultRemoteCooldown = time.Second
+
+var (
+	// ErrNotSummoned will be returned by Remote.Move when zombie is not
+	// in the game e.g. round is over.
+	ErrNotSummoned = errors.New("zombie is not in the game")
+)
+
+// Remote is a zombie controlled by network. Human player moves this zombie
+// with `MOVE <dx> <dy>` command, zombie can crawl only one step in each axis
+// and must rest for Cooldown between moves. Remote zombie stays inside room
+// bounds and dies from one arrow. When room respawns this zombie, new
+// position is announced with WALK event. Zombie name is Name with `remote-`
+// prefix, so it never collides with names of other zombies in the room.
+type Remote struct {
+	Name     string
+	Cooldown time.Duration
+
+	name      string
+	x, y      int64
+	bounds    types.Bounds
+	events    chan types.Event
+	respawned chan struct{}
+	lastMove  time.Time
+	ctx       context.Context
+	kill      context.CancelFunc
+	done      chan struct{}
+	mtx       sync.Mutex
+}
+
+// Summon is used to initialize zombie and attach world events to it. Also
+// context must be passed here to control how long this zombie should exist.
+// Remote zombie cannot be moved outside given bounds.
+func (z *Remote) Summon(ctx context.Context, e chan types.Event, bounds types.Bounds) error {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	z.name = "remote-" + z.Name
+	if z.Name == "" {
+		z.name = "remote-" + PickName()
+	}
+	z.events = e
+	z.bounds = bounds
+	z.ctx, z.kill = context.WithCancel(ctx)
+	z.lastMove = time.Time{}
+
+	log.Printf("zombie '%s' has been summoned!", z.name)
+	return nil
+}
+
+// Run will start this zombie and announce its position.
+func (z *Remote) Run() {
+	z.announce()
+
+	// start living cycle.
+	z.mtx.Lock()
+	z.done = make(chan struct{})
+	go z.startLiving(z.ctx, z.done)
+	z.mtx.Unlock()
+}
+
+// GetName will return zombie name.
+func (z *Remote) GetName() string {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	return z.name
+}
+
+// Hit will be called when player hits this zombie. Remote zombie dies from one
+// hit.
+func (z *Remote) Hit() bool {
+	log.Printf("zombie '%s' got hit", z.GetName())
+	return true
+}
+
+// Kill zombie. Room should call this when we want to force-kill this zombie.
+// Kill will block until zombie stops. Player can leave while room summons
+// this zombie for the next round, so zombie state is taken under lock.
+func (z *Remote) Kill() error {
+	z.mtx.Lock()
+	kill, done := z.kill, z.done
+	z.mtx.Unlock()
+	if kill != nil {
+		kill()
+	}
+	if done != nil {
+		<-done
+	}
+	return nil
+}
+
+// GetPos will return current zombie position.
+func (z *Remote) GetPos() (int64, int64) {
+	return atomic.LoadInt64(&z.x), atomic.LoadInt64(&z.y)
+}
+
+// Reset will move zombie to given position. New position is announced with
+// WALK event, so player controlling this zombie knows where he is.
+func (z *Remote) Reset(x, y int64) error {
+	atomic.StoreInt64(&z.x, x)
+	atomic.StoreInt64(&z.y, y)
+	z.announce()
+	return nil
+}
+
+// Next will announce current position of this zombie. Remote zombie moves
+// only when player tells so.
+func (z *Remote) Next() {
+	z.send(z.walk())
+}
+
+// Move will move zombie by dx and dy. Zombie can crawl only one step in each
+// axis and cannot leave room bounds. ErrCooldown is returned if zombie moved
+// less than Cooldown ago.
+func (z *Remote) Move(dx, dy int64) error {
+	if dx < -1 || dx > 1 || dy < -1 || dy > 1 {
+		return types.ErrOutOfRange
+	}
+
+	z.mtx.Lock()
+	if z.ctx == nil || z.ctx.Err() != nil {
+		z.mtx.Unlock()
+		return ErrNotSummoned
+	}
+	now := time.Now()
+	if now.Sub(z.lastMove) < z.cooldown() {
+		z.mtx.Unlock()
+		return types.ErrCooldown
+	}
+	z.lastMove = now
+	bounds := z.bounds
+	z.mtx.Unlock()
+
+	x, y := z.GetPos()
+	atomic.StoreInt64(&z.x, clamp(x+dx, bounds.MaxX))
+	atomic.StoreInt64(&z.y, clamp(y+dy, bounds.MaxY))
+	z.Next()
+	return nil
+}
+
+// send will pass event to the room. Room is taken under lock, because player
+// can move this zombie while room summons it for the next round.
+func (z *Remote) send(e types.Event) {
+	z.mtx.Lock()
+	ctx, events := z.ctx, z.events
+	z.mtx.Unlock()
+	if ctx == nil {
+		return
+	}
+	log.Printf("zombie '%s' has moved '%s'", e.Actor, e.String())
+	select {
+	case events <- e:
+	case <-ctx.Done():
+	}
+}
+
+func (z *Remote) startLiving(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	respawned := z.respawnSignal()
+	for {
+		select {
+		case <-respawned:
+			z.Next()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (z *Remote) walk() types.Event {
+	return types.Event{
+		Type:  "WALK",
+		Actor: z.GetName(),
+		X:     atomic.LoadInt64(&z.x),
+		Y:     atomic.LoadInt64(&z.y),
+	}
+}
+
+// announce will ask living cycle to announce current position of this zombie.
+func (z *Remote) announce() {
+	select {
+	case z.respawnSignal() <- struct{}{}:
+	default:
+		// position is already going to be announced.
+	}
+}
+
+// respawnSignal will return channel used to announce new position after
+// Reset. Reset can be called before Summon, so channel is created lazily.
+func (z *Remote) respawnSignal() chan struct{} {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	if z.respawned == nil {
+		z.respawned = make(chan struct{}, 1)
+	}
+	return z.respawned
+}
+
+func (z *Remote) cooldown() time.Duration {
+	if z.Cooldown <= 0 {
+		return DefaultRemoteCooldown
+	}
+	return z.Cooldown
+}
+
+// clamp will keep coordinate inside [0, max].
+func clamp(v, max int64) int64 {
+	switch {
+	case v < 0:
+		return 0
+	case v > max:
+		return max
+	}
+	return v
+}
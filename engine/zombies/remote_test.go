@@ -0,0 +1,58 @@
+package zombies_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sheirys/zombebattle/engine/types"
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+func TestRemote(t *testing.T) {
+	events := make(chan types.Event, 1)
+
+	remote := &zombies.Remote{Name: "bob", Cooldown: time.Hour}
+	if err := remote.Move(-1, 0); err != zombies.ErrNotSummoned {
+		t.Errorf("unexpected error before summon. got: %v, want: %v", err, zombies.ErrNotSummoned)
+	}
+
+	remote.Summon(context.Background(), events, types.Bounds{MaxX: 3, MaxY: 2})
+	remote.Reset(3, 2)
+
+	if err := remote.Move(2, 0); err != types.ErrOutOfRange {
+		t.Errorf("unexpected error for long step. got: %v, want: %v", err, types.ErrOutOfRange)
+	}
+
+	// zombie cannot leave the map.
+	if err := remote.Move(1, -1); err != nil {
+		t.Fatalf("unexpected move error: %s", err)
+	}
+	event := <-events
+	if event.String() != "WALK remote-bob 3 1" {
+		t.Errorf("unexpected move. got: '%s', want: 'WALK remote-bob 3 1'", event.String())
+	}
+
+	if err := remote.Move(-1, 0); err != types.ErrCooldown {
+		t.Errorf("unexpected error during cooldown. got: %v, want: %v", err, types.ErrCooldown)
+	}
+
+	// running zombie announces its position and announces it again
+	// when it is respawned.
+	remote.Run()
+	if event = <-events; event.String() != "WALK remote-bob 3 1" {
+		t.Errorf("unexpected announce. got: '%s', want: 'WALK remote-bob 3 1'", event.String())
+	}
+	remote.Reset(3, 0)
+	if event = <-events; event.String() != "WALK remote-bob 3 0" {
+		t.Errorf("unexpected respawn. got: '%s', want: 'WALK remote-bob 3 0'", event.String())
+	}
+
+	if !remote.Hit() {
+		t.Errorf("expected that remote zombie dies from one hit")
+	}
+	remote.Kill()
+	if err := remote.Move(-1, 0); err != zombies.ErrNotSummoned {
+		t.Errorf("unexpected error after kill. got: %v, want: %v", err, zombies.ErrNotSummoned)
+	}
+}
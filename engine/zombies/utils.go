@@ -25,8 +25,8 @@ func PickName() string {
 	return fmt.Sprintf("%s-%s", a, b)
 }
 
-// RandomPos will return random position for zombie. This is used for Hard
-// zombie because he wants to jump like zombie rabbit.
+// RandomPos will return random position for zombie in range [min, max). This
+// is used by Rabbit zombie because he wants to jump like zombie rabbit.
 func RandomPos(min, max int64) int64 {
 	return rand.Int63n(max-min) + min
 }
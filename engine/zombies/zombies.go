@@ -0,0 +1,38 @@
+package zombies
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sheirys/zombebattle/engine/types"
+)
+
+// kinds holds all zombie types that can be created by name e.g. from server
+// configuration file.
+var kinds = map[string]func() types.Zombie{
+	"dummy":   func() types.Zombie { return &Dummy{} },
+	"armored": func() types.Zombie { return &Armored{} },
+	"boss":    func() types.Zombie { return &Boss{} },
+	"crawler": func() types.Zombie { return &Crawler{} },
+	"rabbit":  func() types.Zombie { return &Rabbit{} },
+}
+
+// New will create new zombie by zombie type name e.g.: `crawler`. Type names
+// are case-insensitive.
+func New(kind string) (types.Zombie, error) {
+	create, ok := kinds[strings.ToLower(kind)]
+	if !ok {
+		return nil, fmt.Errorf("unknown zombie type '%s'", kind)
+	}
+	return create(), nil
+}
+
+// Kinds will return sorted zombie type names that can be used with New.
+func Kinds() (names []string) {
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
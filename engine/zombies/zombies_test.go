@@ -0,0 +1,28 @@
+package zombies_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sheirys/zombebattle/engine/zombies"
+)
+
+func TestNew(t *testing.T) {
+	for _, kind := range zombies.Kinds() {
+		zombie, err := zombies.New(kind)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %s", kind, err)
+		}
+		if zombie == nil {
+			t.Errorf("expected zombie for %s", kind)
+		}
+	}
+
+	if _, err := zombies.New("vampire"); err == nil {
+		t.Errorf("expected error for unknown zombie type")
+	}
+
+	if z, _ := zombies.New("Crawler"); reflect.TypeOf(z) != reflect.TypeOf(&zombies.Crawler{}) {
+		t.Errorf("zombie type names should be case-insensitive")
+	}
+}